@@ -22,9 +22,10 @@ type GlyphData struct {
 }
 
 type CompressedFrames struct {
-	FrameCount     int32
-	OrigDataLength int32
-	Data           []byte
+	FrameCount           int32
+	OrigDataLength       int32
+	Data                 []byte
+	CompressionAlgorithm CompressionAlgorithm
 }
 
 type CompressedAtlasData struct {
@@ -163,41 +164,13 @@ func glyphsDictFromBytes(data []byte, frameCount int32) (map[rune]GlyphData, err
 }
 
 func (ad *AtlasData) Compress() (*CompressedAtlasData, error) {
-	framesData, err := glyphsDictToBytes(ad.Glyphs)
-
-	if err != nil {
-		return nil, err
-	}
-
-	compressedFramesData, err := Compress(framesData)
-
-	if err != nil {
-		return nil, err
-	}
-
-	compressedFrames := &CompressedFrames{
-		FrameCount:     int32(len(ad.Glyphs)),
-		OrigDataLength: int32(len(framesData)),
-		Data:           compressedFramesData,
-	}
-
-	compressedSymbolSet, err := ad.SymbolSet.Compress()
-
-	if err != nil {
-		return nil, err
-	}
-
-	return &CompressedAtlasData{
-		CompressedFramesData: compressedFrames,
-		CompressedSymbolSet:  compressedSymbolSet,
-		Size:                 ad.Size,
-		MaxHeight:            ad.MaxHeight,
-		FontName:             ad.FontName,
-	}, nil
+	return DefaultCodec().CompressAtlas(ad)
 }
 
 func (cad *CompressedAtlasData) Decompress() (*AtlasData, error) {
-	framesData, err := Decompress(
+	framesCodec := DefaultCodec()
+	framesCodec.CompressionAlgorithm = cad.CompressedFramesData.CompressionAlgorithm
+	framesData, err := framesCodec.Decompress(
 		cad.CompressedFramesData.Data,
 		int(cad.CompressedFramesData.OrigDataLength))
 
@@ -227,7 +200,13 @@ func (cad *CompressedAtlasData) Decompress() (*AtlasData, error) {
 func (cf *CompressedFrames) ToBytes() ([]byte, error) {
 	buffer := bytes.NewBuffer([]byte{})
 
-	err := binary.Write(buffer, binary.BigEndian, cf.FrameCount)
+	err := writeFormatHeader(buffer, compressedFramesFormatVersion)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(buffer, binary.BigEndian, cf.FrameCount)
 
 	if err != nil {
 		return nil, err
@@ -251,6 +230,12 @@ func (cf *CompressedFrames) ToBytes() ([]byte, error) {
 		return nil, err
 	}
 
+	err = binary.Write(buffer, binary.BigEndian, int32(cf.CompressionAlgorithm))
+
+	if err != nil {
+		return nil, err
+	}
+
 	return buffer.Bytes(), nil
 }
 
@@ -258,7 +243,13 @@ func CompressedFramesFromBytes(data []byte) (*CompressedFrames, error) {
 	buffer := bytes.NewBuffer(data)
 	cf := &CompressedFrames{}
 
-	err := binary.Read(buffer, binary.BigEndian, &cf.FrameCount)
+	version, err := readFormatHeader(buffer, compressedFramesFormatVersion)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Read(buffer, binary.BigEndian, &cf.FrameCount)
 
 	if err != nil {
 		return nil, err
@@ -284,6 +275,23 @@ func CompressedFramesFromBytes(data []byte) (*CompressedFrames, error) {
 		return nil, err
 	}
 
+	// The legacy frames are compressed with
+	// the LZWOrderLSBLitWidth8 algorithm.
+	if version == 0 {
+		cf.CompressionAlgorithm = CompressionAlgorithmLZWOrderLSBLitWidth8
+
+		return cf, nil
+	}
+
+	var compressionAlgorithm int32
+	err = binary.Read(buffer, binary.BigEndian, &compressionAlgorithm)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cf.CompressionAlgorithm = CompressionAlgorithm(compressionAlgorithm)
+
 	return cf, nil
 }
 
@@ -0,0 +1,445 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// The block-compressed formats keep the byte order
+// of the GPU formats, so the endpoints and the
+// indices are little-endian unlike the rest
+// of the serialized data.
+
+const (
+	// blockSize is the side of the
+	// square block in pixels.
+	blockSize = 4
+	// bc1BlockBytes is the size
+	// of the encoded BC1 block.
+	bc1BlockBytes = 8
+	// bc3BlockBytes is the size
+	// of the encoded BC3 block.
+	bc3BlockBytes = 16
+)
+
+// isBlockCompressed reports whether the pixel
+// format stores the pixels in 4×4 blocks.
+func isBlockCompressed(pixFormat PixFormat) bool {
+	return pixFormat == PixFormatBC1 || pixFormat == PixFormatBC3
+}
+
+// blockBytes returns the size of the
+// encoded block of the pixel format.
+func blockBytes(pixFormat PixFormat) int {
+	switch pixFormat {
+	case PixFormatBC1:
+		return bc1BlockBytes
+
+	case PixFormatBC3:
+		return bc3BlockBytes
+
+	default:
+		return 0
+	}
+}
+
+// encodeBlocks encodes the RGBA pixels of the
+// width×height picture into the blocks of the
+// BC1 or BC3 format. The blocks on the right
+// and the top edges are padded by repeating
+// the edge pixels.
+func encodeBlocks(pix []byte, width, height int, pixFormat PixFormat) []byte {
+	columns := (width + blockSize - 1) / blockSize
+	rows := (height + blockSize - 1) / blockSize
+	size := blockBytes(pixFormat)
+	encoded := make([]byte, columns*rows*size)
+	var block [blockSize * blockSize][4]uint8
+
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			for i := range block {
+				x := min(column*blockSize+i%blockSize, width-1)
+				y := min(row*blockSize+i/blockSize, height-1)
+				offset := (y*width + x) * 4
+				block[i] = [4]uint8(pix[offset : offset+4])
+			}
+
+			dst := encoded[(row*columns+column)*size:]
+
+			switch pixFormat {
+			case PixFormatBC1:
+				encodeColorBlock(dst, &block, true)
+
+			case PixFormatBC3:
+				encodeAlphaBlock(dst, &block)
+				encodeColorBlock(dst[8:], &block, false)
+			}
+		}
+	}
+
+	return encoded
+}
+
+// decodeBlocks decodes the blocks of the BC1 or
+// BC3 format into the RGBA pixels of the
+// width×height picture.
+func decodeBlocks(data []byte, width, height int, pixFormat PixFormat) ([]byte, error) {
+	if len(data) != pixFormat.PixSize(width, height) {
+		return nil, fmt.Errorf(
+			"%d bytes don't match the %dx%d picture of format '%s'",
+			len(data), width, height, pixFormat)
+	}
+
+	columns := (width + blockSize - 1) / blockSize
+	rows := (height + blockSize - 1) / blockSize
+	size := blockBytes(pixFormat)
+	pix := make([]byte, width*height*4)
+	var block [blockSize * blockSize][4]uint8
+
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			src := data[(row*columns+column)*size:]
+
+			switch pixFormat {
+			case PixFormatBC1:
+				decodeColorBlock(src, &block, true)
+
+			case PixFormatBC3:
+				decodeColorBlock(src[8:], &block, false)
+				decodeAlphaBlock(src, &block)
+			}
+
+			for i := range block {
+				x := column*blockSize + i%blockSize
+				y := row*blockSize + i/blockSize
+
+				if x < width && y < height {
+					offset := (y*width + x) * 4
+					copy(pix[offset:offset+4], block[i][:])
+				}
+			}
+		}
+	}
+
+	return pix, nil
+}
+
+// encodeColorBlock encodes the colors of the block
+// with the endpoints lying on the principal axis
+// of the colors. If punchThrough is true, the
+// pixels with alpha below 128 become transparent.
+func encodeColorBlock(dst []byte, block *[blockSize * blockSize][4]uint8, punchThrough bool) {
+	transparent := false
+
+	if punchThrough {
+		for _, pixel := range block {
+			if pixel[3] < 128 {
+				transparent = true
+			}
+		}
+	}
+
+	color0, color1 := principalEndpoints(block, punchThrough)
+
+	// The order of the endpoints selects the mode: the 4-color
+	// one if color0 > color1, the 3-color one with the
+	// transparent index 3 otherwise.
+	if (color0 < color1) != transparent && color0 != color1 {
+		color0, color1 = color1, color0
+	}
+
+	palette := colorPalette(color0, color1, !(color0 > color1))
+	var indices uint32
+
+	for i := len(block) - 1; i >= 0; i-- {
+		pixel := block[i]
+		index := 3
+
+		if !transparent || pixel[3] >= 128 {
+			index = nearestBlockColor(palette, pixel, !(color0 > color1))
+		}
+
+		indices = indices<<2 | uint32(index)
+	}
+
+	binary.LittleEndian.PutUint16(dst[0:], color0)
+	binary.LittleEndian.PutUint16(dst[2:], color1)
+	binary.LittleEndian.PutUint32(dst[4:], indices)
+}
+
+// decodeColorBlock decodes the colors of the block.
+// If punchThrough is false, the block is always
+// decoded in the 4-color mode as BC3 requires.
+func decodeColorBlock(src []byte, block *[blockSize * blockSize][4]uint8, punchThrough bool) {
+	color0 := binary.LittleEndian.Uint16(src[0:])
+	color1 := binary.LittleEndian.Uint16(src[2:])
+	indices := binary.LittleEndian.Uint32(src[4:])
+	threeColor := punchThrough && color0 <= color1
+	palette := colorPalette(color0, color1, threeColor)
+
+	for i := range block {
+		block[i] = palette[indices>>(2*i)&0x3]
+	}
+}
+
+// colorPalette returns the four colors the
+// indices of the color block refer to.
+func colorPalette(color0, color1 uint16, threeColor bool) [4][4]uint8 {
+	c0 := unpack565(color0)
+	c1 := unpack565(color1)
+	var palette [4][4]uint8
+	palette[0] = c0
+	palette[1] = c1
+
+	for ch := 0; ch < 3; ch++ {
+		if threeColor {
+			palette[2][ch] = uint8((int(c0[ch]) + int(c1[ch])) / 2)
+			palette[3][ch] = 0
+		} else {
+			palette[2][ch] = uint8((2*int(c0[ch]) + int(c1[ch])) / 3)
+			palette[3][ch] = uint8((int(c0[ch]) + 2*int(c1[ch])) / 3)
+		}
+	}
+
+	palette[2][3] = 255
+
+	if threeColor {
+		palette[3][3] = 0
+	} else {
+		palette[3][3] = 255
+	}
+
+	return palette
+}
+
+// nearestBlockColor returns the index of the opaque
+// palette color closest to the pixel.
+func nearestBlockColor(palette [4][4]uint8, pixel [4]uint8, threeColor bool) int {
+	count := 4
+
+	if threeColor {
+		count = 3
+	}
+
+	nearest, nearestDistance := 0, -1
+
+	for i := 0; i < count; i++ {
+		distance := 0
+
+		for ch := 0; ch < 3; ch++ {
+			d := int(pixel[ch]) - int(palette[i][ch])
+			distance += d * d
+		}
+
+		if nearestDistance < 0 || distance < nearestDistance {
+			nearest, nearestDistance = i, distance
+		}
+	}
+
+	return nearest
+}
+
+// principalEndpoints projects the colors of the block
+// onto their principal axis and returns the extreme
+// projections packed into RGB565. The transparent
+// pixels are skipped if punchThrough is true.
+func principalEndpoints(block *[blockSize * blockSize][4]uint8, punchThrough bool) (uint16, uint16) {
+	var mean [3]float64
+	count := 0
+
+	for _, pixel := range block {
+		if punchThrough && pixel[3] < 128 {
+			continue
+		}
+
+		for ch := 0; ch < 3; ch++ {
+			mean[ch] += float64(pixel[ch])
+		}
+
+		count++
+	}
+
+	if count == 0 {
+		return 0, 0
+	}
+
+	for ch := 0; ch < 3; ch++ {
+		mean[ch] /= float64(count)
+	}
+
+	var covariance [3][3]float64
+
+	for _, pixel := range block {
+		if punchThrough && pixel[3] < 128 {
+			continue
+		}
+
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				covariance[i][j] += (float64(pixel[i]) - mean[i]) *
+					(float64(pixel[j]) - mean[j])
+			}
+		}
+	}
+
+	// Find the principal axis with the power
+	// iteration which is deterministic and
+	// converges fast enough for 3×3.
+	axis := [3]float64{1, 1, 1}
+
+	for iteration := 0; iteration < 8; iteration++ {
+		var next [3]float64
+		norm := 0.0
+
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				next[i] += covariance[i][j] * axis[j]
+			}
+
+			norm = max(norm, math.Abs(next[i]))
+		}
+
+		if norm == 0 {
+			break
+		}
+
+		for i := 0; i < 3; i++ {
+			axis[i] = next[i] / norm
+		}
+	}
+
+	minProjection, maxProjection := 0.0, 0.0
+	first := true
+
+	for _, pixel := range block {
+		if punchThrough && pixel[3] < 128 {
+			continue
+		}
+
+		projection := 0.0
+
+		for ch := 0; ch < 3; ch++ {
+			projection += (float64(pixel[ch]) - mean[ch]) * axis[ch]
+		}
+
+		if first || projection < minProjection {
+			minProjection = projection
+		}
+
+		if first || projection > maxProjection {
+			maxProjection = projection
+		}
+
+		first = false
+	}
+
+	lengthSquared := axis[0]*axis[0] + axis[1]*axis[1] + axis[2]*axis[2]
+
+	if lengthSquared == 0 {
+		lengthSquared = 1
+	}
+
+	var low, high [3]float64
+
+	for ch := 0; ch < 3; ch++ {
+		low[ch] = mean[ch] + axis[ch]*minProjection/lengthSquared
+		high[ch] = mean[ch] + axis[ch]*maxProjection/lengthSquared
+	}
+
+	return pack565(high), pack565(low)
+}
+
+// encodeAlphaBlock encodes the alpha of the block
+// with the 8-level interpolation between the
+// minimal and the maximal alpha.
+func encodeAlphaBlock(dst []byte, block *[blockSize * blockSize][4]uint8) {
+	alpha0, alpha1 := uint8(0), uint8(255)
+
+	for _, pixel := range block {
+		alpha0 = max(alpha0, pixel[3])
+		alpha1 = min(alpha1, pixel[3])
+	}
+
+	palette := alphaPalette(alpha0, alpha1)
+	var indices uint64
+
+	for i := len(block) - 1; i >= 0; i-- {
+		nearest, nearestDistance := 0, 256
+
+		for j, alpha := range palette {
+			if distance := abs(int(block[i][3]) - int(alpha)); distance < nearestDistance {
+				nearest, nearestDistance = j, distance
+			}
+		}
+
+		indices = indices<<3 | uint64(nearest)
+	}
+
+	dst[0] = alpha0
+	dst[1] = alpha1
+
+	for i := 0; i < 6; i++ {
+		dst[2+i] = uint8(indices >> (8 * i))
+	}
+}
+
+// decodeAlphaBlock decodes the
+// alpha of the block.
+func decodeAlphaBlock(src []byte, block *[blockSize * blockSize][4]uint8) {
+	palette := alphaPalette(src[0], src[1])
+	var indices uint64
+
+	for i := 0; i < 6; i++ {
+		indices |= uint64(src[2+i]) << (8 * i)
+	}
+
+	for i := range block {
+		block[i][3] = palette[indices>>(3*i)&0x7]
+	}
+}
+
+// alphaPalette returns the eight alpha
+// values the indices of the alpha block
+// refer to.
+func alphaPalette(alpha0, alpha1 uint8) [8]uint8 {
+	var palette [8]uint8
+	palette[0] = alpha0
+	palette[1] = alpha1
+	a0, a1 := int(alpha0), int(alpha1)
+
+	if alpha0 > alpha1 {
+		for i := 1; i < 7; i++ {
+			palette[i+1] = uint8(((7-i)*a0 + i*a1) / 7)
+		}
+	} else {
+		for i := 1; i < 5; i++ {
+			palette[i+1] = uint8(((5-i)*a0 + i*a1) / 5)
+		}
+
+		palette[6] = 0
+		palette[7] = 255
+	}
+
+	return palette
+}
+
+// pack565 rounds the RGB color
+// and packs it into RGB565.
+func pack565(c [3]float64) uint16 {
+	quantize := func(v float64, maxValue float64) uint16 {
+		return uint16(max(min(v*maxValue/255+0.5, maxValue), 0))
+	}
+
+	return quantize(c[0], 31)<<11 | quantize(c[1], 63)<<5 | quantize(c[2], 31)
+}
+
+// unpack565 expands the RGB565
+// color to the opaque RGBA.
+func unpack565(v uint16) [4]uint8 {
+	r := uint8(v >> 11)
+	g := uint8(v >> 5 & 0x3F)
+	b := uint8(v & 0x1F)
+
+	return [4]uint8{r<<3 | r>>2, g<<2 | g>>4, b<<3 | b>>2, 255}
+}
@@ -0,0 +1,71 @@
+package codec_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockCompression(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(t, err)
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+
+	for _, pixFormat := range []codec.PixFormat{codec.PixFormatBC1, codec.PixFormatBC3} {
+		blockPicture, err := picture.ConvertTo(pixFormat)
+		assert.Nil(t, err)
+		assert.Equal(t, pixFormat.PixSize(int(picture.Width), int(picture.Height)),
+			len(blockPicture.Pix))
+
+		c := codec.NewCodec(codec.HashAlgorithmKeccak256,
+			pixFormat, codec.CompressionAlgorithmLZWOrderLSBLitWidth8)
+		imagePicture, err := c.NewPictureFromImage(img)
+		assert.Nil(t, err)
+		assert.Equal(t, blockPicture.Pix, imagePicture.Pix)
+		assert.Equal(t, blockPicture.Hash, imagePicture.Hash)
+
+		compressedPicture, err := blockPicture.Compress()
+		assert.Nil(t, err)
+		rgbaPicture, err := compressedPicture.DecompressAs(codec.PixFormatRGBA)
+		assert.Nil(t, err)
+		assert.Greater(t, psnr(picture.Pix, rgbaPicture.Pix), 32.0, pixFormat.String())
+	}
+}
+
+func TestBlockCompressionAlpha(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 30, 18))
+
+	for y := 0; y < 18; y++ {
+		for x := 0; x < 30; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 200, G: 40, B: 90, A: uint8(x * 8)})
+		}
+	}
+
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+
+	bc3Picture, err := picture.ConvertTo(codec.PixFormatBC3)
+	assert.Nil(t, err)
+	bc3RGBAPicture, err := bc3Picture.ConvertTo(codec.PixFormatRGBA)
+	assert.Nil(t, err)
+
+	bc1Picture, err := picture.ConvertTo(codec.PixFormatBC1)
+	assert.Nil(t, err)
+	bc1RGBAPicture, err := bc1Picture.ConvertTo(codec.PixFormatRGBA)
+	assert.Nil(t, err)
+
+	for i := 3; i < len(picture.Pix); i += 4 {
+		assert.InDelta(t, picture.Pix[i], bc3RGBAPicture.Pix[i], 5)
+
+		if picture.Pix[i] < 128 {
+			assert.Equal(t, uint8(0), bc1RGBAPicture.Pix[i])
+		} else {
+			assert.Equal(t, uint8(255), bc1RGBAPicture.Pix[i])
+		}
+	}
+}
@@ -0,0 +1,417 @@
+package codec
+
+import (
+	"fmt"
+	"hash"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Codec carries the hash, pixel format and
+// compression settings used to encode resources.
+//
+// Unlike the Consented* package variables,
+// a codec is never mutated by its methods,
+// so a single codec can be safely shared
+// between goroutines.
+type Codec struct {
+	HashAlgorithm        HashAlgorithm
+	PixFormat            PixFormat
+	CompressionAlgorithm CompressionAlgorithm
+	// CompressionLevel is passed to the compression
+	// algorithms supporting compression levels and
+	// ignored by the rest of them.
+	CompressionLevel int
+	// PixFilter is applied to the pixels
+	// of the pictures before compression.
+	PixFilter PixFilter
+	// ChunkSize makes the pictures compress as
+	// independent chunks of the given number
+	// of bytes if it's positive.
+	ChunkSize int
+	// Workers is the number of goroutines compressing
+	// and decompressing the chunks. Zero stands for
+	// GOMAXPROCS.
+	Workers int
+	// Origin is the corner the pictures
+	// created out of the images start from.
+	Origin Origin
+}
+
+// NewCodec creates a new codec with
+// the specified algorithms.
+func NewCodec(
+	hashAlgorithm HashAlgorithm,
+	pixFormat PixFormat,
+	compressionAlgorithm CompressionAlgorithm,
+) *Codec {
+	return &Codec{
+		HashAlgorithm:        hashAlgorithm,
+		PixFormat:            pixFormat,
+		CompressionAlgorithm: compressionAlgorithm,
+	}
+}
+
+// DefaultCodec returns a new codec
+// configured with the consented algorithms.
+func DefaultCodec() *Codec {
+	return NewCodec(
+		ConsentedHashAlgorithm,
+		ConsentedPixFormat,
+		ConsentedCompressionAlgorithm)
+}
+
+// Compress compresses the given data using
+// the compression algorithm of the codec.
+func (c *Codec) Compress(in []byte) ([]byte, error) {
+	entry, ok := lookupCompressionAlgorithm(c.CompressionAlgorithm)
+
+	if !ok {
+		return nil, fmt.Errorf(
+			"compression algorithm %d not found",
+			c.CompressionAlgorithm)
+	}
+
+	if c.CompressionLevel != CompressionLevelDefault &&
+		entry.compressLevel != nil {
+		return entry.compressLevel(in, c.CompressionLevel)
+	}
+
+	return entry.compress(in)
+}
+
+// Decompress decompresses the given data using
+// the compression algorithm of the codec.
+func (c *Codec) Decompress(in []byte, sourceSize int) ([]byte, error) {
+	_, decompress, ok := LookupCompressionAlgorithm(c.CompressionAlgorithm)
+
+	if !ok {
+		return nil, fmt.Errorf(
+			"decompression algorithm %d not found",
+			c.CompressionAlgorithm)
+	}
+
+	return decompress(in, sourceSize)
+}
+
+// Hash computes the hash sum of the given
+// data using the hash algorithm of the codec.
+func (c *Codec) Hash(in []byte) ([]byte, error) {
+	hasher, err := c.NewHasher()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return hashWith(hasher, in)
+}
+
+// NewHasher returns a new hash state of the
+// hash algorithm of the codec.
+func (c *Codec) NewHasher() (hash.Hash, error) {
+	newHash, ok := LookupHashAlgorithm(c.HashAlgorithm)
+
+	if !ok {
+		return nil, fmt.Errorf(
+			"hash algorithm %d not found",
+			c.HashAlgorithm)
+	}
+
+	return newHash(), nil
+}
+
+// NewPictureFromImage creates a new picture
+// out of the image using the settings of the codec.
+// The pixels within the image bounds are taken
+// with straight alpha and stored in the pixel
+// format of the codec, so the codec with the
+// PixFormatRGBAPremultiplied format
+// premultiplies them at build time.
+func (c *Codec) NewPictureFromImage(img image.Image) (*PictureData, error) {
+	if _, ok := pixCodecs[c.PixFormat]; !ok {
+		// The paletted and block-compressed
+		// pixels are built out of RGBA.
+		rgbaCodec := *c
+		rgbaCodec.PixFormat = PixFormatRGBA
+		picture, err := rgbaCodec.NewPictureFromImage(img)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return picture.ConvertTo(c.PixFormat)
+	}
+
+	var pix []byte
+
+	switch c.PixFormat {
+	case PixFormatRGBA, PixFormatRGB, PixFormatL8:
+		// The 8-bit formats are packed straight
+		// out of the 8-bit image without widening
+		// it to 16 bits first.
+		imgNRGBA := toNRGBA(img)
+
+		if c.Origin == OriginBottomLeft {
+			flipRows(imgNRGBA.Pix, imgNRGBA.Stride)
+		}
+
+		pix = packNRGBA(imgNRGBA.Pix, c.PixFormat)
+
+	default:
+		imgNRGBA64 := toNRGBA64(img)
+
+		if c.Origin == OriginBottomLeft {
+			flipRows(imgNRGBA64.Pix, imgNRGBA64.Stride)
+		}
+
+		var err error
+		pix, err = convertPix(imgNRGBA64.Pix, PixFormatRGBA64, c.PixFormat)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hash, err := c.Hash(pix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PictureData{
+		Width:         int32(img.Bounds().Dx()),
+		Height:        int32(img.Bounds().Dy()),
+		Pix:           pix,
+		Hash:          hash,
+		PixFormat:     c.PixFormat,
+		HashAlgorithm: c.HashAlgorithm,
+		Origin:        c.Origin,
+	}, nil
+}
+
+// toNRGBA copies the image into the non-premultiplied
+// 8-bit RGBA image. The images with non-premultiplied
+// alpha are copied directly for the same reason
+// as in toNRGBA64.
+func toNRGBA(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	imgNRGBA := image.NewNRGBA(image.Rect(0, 0,
+		bounds.Dx(), bounds.Dy()))
+
+	switch src := img.(type) {
+	case *image.NRGBA:
+		rowSize := bounds.Dx() * 4
+
+		for y := 0; y < bounds.Dy(); y++ {
+			offset := src.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+			copy(imgNRGBA.Pix[y*imgNRGBA.Stride:], src.Pix[offset:offset+rowSize])
+		}
+
+	case *image.NRGBA64:
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				c := src.NRGBA64At(bounds.Min.X+x, bounds.Min.Y+y)
+				imgNRGBA.SetNRGBA(x, y, color.NRGBA{
+					R: narrow16(c.R),
+					G: narrow16(c.G),
+					B: narrow16(c.B),
+					A: narrow16(c.A),
+				})
+			}
+		}
+
+	default:
+		draw.Draw(imgNRGBA, imgNRGBA.Bounds(),
+			img, bounds.Min, draw.Src)
+	}
+
+	return imgNRGBA
+}
+
+// packNRGBA packs the non-premultiplied 8-bit RGBA
+// pixels into the 8-bit format the same way
+// convertPix does. The RGBA pixels are
+// returned as they are.
+func packNRGBA(pix []byte, pixFormat PixFormat) []byte {
+	switch pixFormat {
+	case PixFormatRGB:
+		packed := make([]byte, len(pix)/4*3)
+
+		for i, j := 0, 0; i < len(pix); i, j = i+4, j+3 {
+			copy(packed[j:j+3], pix[i:i+3])
+		}
+
+		return packed
+
+	case PixFormatL8:
+		packed := make([]byte, len(pix)/4)
+
+		for i := range packed {
+			packed[i] = narrow16(luminance(color.NRGBA64{
+				R: widen8(pix[i*4]),
+				G: widen8(pix[i*4+1]),
+				B: widen8(pix[i*4+2]),
+			}))
+		}
+
+		return packed
+
+	default:
+		return pix
+	}
+}
+
+// toNRGBA64 copies the image into the non-premultiplied
+// 16-bit RGBA image. The images with non-premultiplied
+// alpha are copied directly because drawing them
+// goes through the premultiplied colors and loses
+// the precision of the translucent pixels.
+func toNRGBA64(img image.Image) *image.NRGBA64 {
+	bounds := img.Bounds()
+	imgNRGBA64 := image.NewNRGBA64(image.Rect(0, 0,
+		bounds.Dx(), bounds.Dy()))
+
+	switch src := img.(type) {
+	case *image.NRGBA:
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				c := src.NRGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+				imgNRGBA64.SetNRGBA64(x, y, color.NRGBA64{
+					R: widen8(c.R),
+					G: widen8(c.G),
+					B: widen8(c.B),
+					A: widen8(c.A),
+				})
+			}
+		}
+
+	case *image.NRGBA64:
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				imgNRGBA64.SetNRGBA64(x, y,
+					src.NRGBA64At(bounds.Min.X+x, bounds.Min.Y+y))
+			}
+		}
+
+	default:
+		draw.Draw(imgNRGBA64, imgNRGBA64.Bounds(),
+			img, bounds.Min, draw.Src)
+	}
+
+	return imgNRGBA64
+}
+
+// CompressPicture compresses the pixels of the
+// picture using the compression algorithm of the codec.
+func (c *Codec) CompressPicture(picture *PictureData) (*CompressedPictureData, error) {
+	filteredPix, err := c.filterPicturePix(picture)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.compressFilteredPix(picture, filteredPix)
+}
+
+// compressFilteredPix compresses the filtered pixels
+// of the picture splitting them into chunks if the
+// chunk size of the codec is set.
+func (c *Codec) compressFilteredPix(picture *PictureData, filteredPix []byte) (*CompressedPictureData, error) {
+	if c.ChunkSize > 0 {
+		compressedPix, chunkSizes, err := c.compressChunks(filteredPix, c.ChunkSize)
+
+		if err != nil {
+			return nil, err
+		}
+
+		compressedPicture := c.newCompressedPicture(picture, compressedPix)
+		compressedPicture.ChunkSize = int32(c.ChunkSize)
+		compressedPicture.CompressedChunkSizes = chunkSizes
+
+		return compressedPicture, nil
+	}
+
+	compressedPix, err := c.Compress(filteredPix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.newCompressedPicture(picture, compressedPix), nil
+}
+
+// filterPicturePix makes sure the pixels match
+// the size of the picture and applies the pixel
+// filter of the codec to them.
+func (c *Codec) filterPicturePix(picture *PictureData) ([]byte, error) {
+	pixSize := picture.PixFormat.PixSize(int(picture.Width), int(picture.Height))
+
+	if len(picture.Pix) != pixSize {
+		return nil, fmt.Errorf(
+			"pixel data of %d bytes doesn't match the %dx%d picture of format '%s'",
+			len(picture.Pix), picture.Width, picture.Height, picture.PixFormat)
+	}
+
+	return filterPix(picture.Pix,
+		int(picture.Width), int(picture.Height),
+		picture.PixFormat.BytesPerPixel(), c.PixFilter)
+}
+
+// newCompressedPicture creates a new compressed
+// picture out of the picture and its pixels
+// compressed with the settings of the codec.
+func (c *Codec) newCompressedPicture(picture *PictureData, compressedPix []byte) *CompressedPictureData {
+	return &CompressedPictureData{
+		Width:                 picture.Width,
+		Height:                picture.Height,
+		OriginalPixSize:       int32(picture.PixFormat.PixSize(int(picture.Width), int(picture.Height))),
+		CompressedPix:         compressedPix,
+		OriginalHash:          picture.Hash,
+		OriginalPixFormat:     picture.PixFormat,
+		OriginalHashAlgorithm: picture.HashAlgorithm,
+		CompressionAlgorithm:  c.CompressionAlgorithm,
+		PixFilter:             c.PixFilter,
+		ColorSpace:            picture.ColorSpace,
+		Palette:               picture.Palette,
+		Origin:                picture.Origin,
+	}
+}
+
+// CompressAtlas compresses the glyphs and the
+// symbol set of the atlas using the compression
+// algorithm of the codec.
+func (c *Codec) CompressAtlas(ad *AtlasData) (*CompressedAtlasData, error) {
+	framesData, err := glyphsDictToBytes(ad.Glyphs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compressedFramesData, err := c.Compress(framesData)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compressedFrames := &CompressedFrames{
+		FrameCount:           int32(len(ad.Glyphs)),
+		OrigDataLength:       int32(len(framesData)),
+		Data:                 compressedFramesData,
+		CompressionAlgorithm: c.CompressionAlgorithm,
+	}
+
+	compressedSymbolSet, err := c.CompressPicture(ad.SymbolSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompressedAtlasData{
+		CompressedFramesData: compressedFrames,
+		CompressedSymbolSet:  compressedSymbolSet,
+		Size:                 ad.Size,
+		MaxHeight:            ad.MaxHeight,
+		FontName:             ad.FontName,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package codec_test
+
+import (
+	"bytes"
+	"image"
+	"sync"
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecompressPicturesConcurrently(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(t, err)
+
+	keccakCodec := codec.NewCodec(codec.HashAlgorithmKeccak256,
+		codec.PixFormatRGBA, codec.CompressionAlgorithmLZWOrderLSBLitWidth8)
+	sha256Codec := codec.NewCodec(codec.HashAlgorithmSHA256,
+		codec.PixFormatRGBA, codec.CompressionAlgorithmLZWOrderLSBLitWidth8)
+	compressedPictures := []*codec.CompressedPictureData{}
+
+	for _, c := range []*codec.Codec{keccakCodec, sha256Codec} {
+		picture, err := c.NewPictureFromImage(img)
+		assert.Nil(t, err)
+		compressedPicture, err := c.CompressPicture(picture)
+		assert.Nil(t, err)
+
+		compressedPictures = append(compressedPictures, compressedPicture)
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 32; i++ {
+		compressedPicture := compressedPictures[i%len(compressedPictures)]
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			picture, err := compressedPicture.Decompress()
+			assert.Nil(t, err)
+			assert.Equal(t, compressedPicture.OriginalHashAlgorithm, picture.HashAlgorithm)
+			assert.Equal(t, compressedPicture.OriginalHash, picture.Hash)
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,145 @@
+package codec
+
+import (
+	"image/color"
+	"math"
+	"sync"
+)
+
+// ColorSpace is the transfer function
+// the color channels of the picture
+// are encoded with.
+type ColorSpace int
+
+const (
+	ColorSpaceSRGB   ColorSpace = iota // ColorSpaceSRGB is the gamma-encoded sRGB.
+	ColorSpaceLinear                   // ColorSpaceLinear is the linear-light sRGB.
+)
+
+func (colorSpace ColorSpace) String() string {
+	switch colorSpace {
+	case ColorSpaceSRGB:
+		return "sRGB"
+
+	case ColorSpaceLinear:
+		return "linear"
+
+	default:
+		return ""
+	}
+}
+
+var (
+	// srgbToLinearTable maps every 16-bit
+	// sRGB channel value to the linear one.
+	srgbToLinearTable = sync.OnceValue(func() []uint16 {
+		return transferTable(srgbToLinear)
+	})
+	// linearToSRGBTable maps every 16-bit
+	// linear channel value to the sRGB one.
+	linearToSRGBTable = sync.OnceValue(func() []uint16 {
+		return transferTable(linearToSRGB)
+	})
+)
+
+// srgbToLinear is the sRGB electro-optical
+// transfer function for the value in [0; 1].
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB is the inverse of the sRGB
+// transfer function for the value in [0; 1].
+func linearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// transferTable tabulates the transfer
+// function for all the 16-bit values.
+func transferTable(transfer func(float64) float64) []uint16 {
+	table := make([]uint16, 0x10000)
+
+	for i := range table {
+		table[i] = uint16(math.Round(
+			transfer(float64(i)/0xFFFF) * 0xFFFF))
+	}
+
+	return table
+}
+
+// ToLinear returns a new picture with the color
+// channels decoded from sRGB to linear light.
+// The alpha channel stays intact. The 8-bit
+// formats lose the precision of the dark tones,
+// so PixFormatRGBA64 is preferred for the
+// linear pictures.
+func (picture *PictureData) ToLinear() (*PictureData, error) {
+	return picture.transfer(ColorSpaceLinear, srgbToLinearTable())
+}
+
+// ToSRGB returns a new picture with the color
+// channels encoded from linear light to sRGB.
+// The alpha channel stays intact.
+func (picture *PictureData) ToSRGB() (*PictureData, error) {
+	return picture.transfer(ColorSpaceSRGB, linearToSRGBTable())
+}
+
+// transfer applies the tabulated transfer function
+// to the color channels of the picture unless it's
+// already in the target color space.
+func (picture *PictureData) transfer(colorSpace ColorSpace, table []uint16) (*PictureData, error) {
+	if picture.ColorSpace == colorSpace {
+		return picture.ConvertTo(picture.PixFormat)
+	}
+
+	transfer := func(c color.NRGBA64) color.NRGBA64 {
+		return color.NRGBA64{
+			R: table[c.R],
+			G: table[c.G],
+			B: table[c.B],
+			A: c.A,
+		}
+	}
+
+	if picture.PixFormat == PixFormatPaletted {
+		// Only the palette colors
+		// have to be transferred.
+		paletteData, err := mapPix(paletteBytes(picture.Palette),
+			PixFormatRGBA, transfer)
+
+		if err != nil {
+			return nil, err
+		}
+
+		transferred := *picture
+		transferred.Palette = paletteFromBytes(paletteData)
+		transferred.ColorSpace = colorSpace
+
+		return transferred.withPix(append([]byte{}, picture.Pix...),
+			PixFormatPaletted)
+	}
+
+	pix, err := mapPix(picture.Pix, picture.PixFormat, transfer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transferred, err := picture.withPix(pix, picture.PixFormat)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transferred.ColorSpace = colorSpace
+
+	return transferred, nil
+}
@@ -0,0 +1,55 @@
+package codec_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorSpaceConversion(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(t, err)
+	c := codec.NewCodec(codec.HashAlgorithmSHA256,
+		codec.PixFormatRGBA64, codec.CompressionAlgorithmDeflate)
+	picture, err := c.NewPictureFromImage(img)
+	assert.Nil(t, err)
+	assert.Equal(t, codec.ColorSpaceSRGB, picture.ColorSpace)
+
+	linearPicture, err := picture.ToLinear()
+	assert.Nil(t, err)
+	assert.Equal(t, codec.ColorSpaceLinear, linearPicture.ColorSpace)
+	assert.NotEqual(t, picture.Hash, linearPicture.Hash)
+
+	compressedPicture, err := c.CompressPicture(linearPicture)
+	assert.Nil(t, err)
+	data, err := compressedPicture.ToBytes()
+	assert.Nil(t, err)
+	deserializedPicture, err := codec.CompressedPictureFromBytes(data)
+	assert.Nil(t, err)
+	assert.Equal(t, codec.ColorSpaceLinear, deserializedPicture.ColorSpace)
+	decompressedPicture, err := deserializedPicture.Decompress()
+	assert.Nil(t, err)
+	assert.Equal(t, codec.ColorSpaceLinear, decompressedPicture.ColorSpace)
+
+	srgbPicture, err := decompressedPicture.ToSRGB()
+	assert.Nil(t, err)
+	assert.Equal(t, codec.ColorSpaceSRGB, srgbPicture.ColorSpace)
+	assert.Equal(t, len(picture.Pix), len(srgbPicture.Pix))
+
+	// The 16-bit channels survive the round trip up
+	// to the rounding of the linear dark tones
+	// which are 12.92 times denser than in sRGB.
+	maxDelta := 0
+
+	for i := 0; i < len(picture.Pix); i += 2 {
+		original := binary.BigEndian.Uint16(picture.Pix[i:])
+		restored := binary.BigEndian.Uint16(srgbPicture.Pix[i:])
+		maxDelta = max(maxDelta, abs(int(original)-int(restored)))
+	}
+
+	assert.LessOrEqual(t, maxDelta, 7)
+}
@@ -1,18 +1,285 @@
 package codec
 
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
 type CompressionAlgorithm int
 
 const (
 	CompressionAlgorithmLZWOrderLSBLitWidth8 CompressionAlgorithm = iota // CompressionAlgorithmLZWOrderLSBLitWidth8 stands for the LZW compression algorithm with LSB for order and 8 for literal width.
+	CompressionAlgorithmDeflate                                          // CompressionAlgorithmDeflate stands for the raw DEFLATE compression algorithm.
+	CompressionAlgorithmZlib                                             // CompressionAlgorithmZlib stands for the DEFLATE compression algorithm in the zlib format.
+	CompressionAlgorithmGzip                                             // CompressionAlgorithmGzip stands for the DEFLATE compression algorithm in the gzip format.
+	CompressionAlgorithmLZ4                                              // CompressionAlgorithmLZ4 stands for the LZ4 block compression algorithm.
+)
+
+const (
+	CompressionLevelDefault         = 0 // CompressionLevelDefault makes the compression algorithm use its own default level.
+	CompressionLevelBestSpeed       = 1 // CompressionLevelBestSpeed is the fastest compression level.
+	CompressionLevelBestCompression = 9 // CompressionLevelBestCompression is the compression level yielding the smallest output.
+)
+
+// CompressFunc compresses the given data.
+type CompressFunc func(in []byte) ([]byte, error)
+
+// CompressLevelFunc compresses the given
+// data with the specified compression level.
+type CompressLevelFunc func(in []byte, level int) ([]byte, error)
+
+// DecompressFunc decompresses the given data
+// which is sourceSize bytes long when decompressed.
+type DecompressFunc func(in []byte, sourceSize int) ([]byte, error)
+
+// compressionAlgorithmEntry is a record of
+// the compression algorithm registry.
+type compressionAlgorithmEntry struct {
+	name           string
+	compress       CompressFunc
+	compressLevel  CompressLevelFunc
+	decompress     DecompressFunc
+	newWriter      CompressWriterFunc
+	newReader      DecompressReaderFunc
+	blockOnly      bool    // blockOnly marks the formats without framing which cannot be streamed.
+	decompressCost float64 // decompressCost is the decompression time relative to LZ4, 0 if unknown.
+}
+
+var (
+	// compressionAlgorithmsMutex guards the
+	// compression algorithm registry.
+	compressionAlgorithmsMutex sync.RWMutex
+	// compressionAlgorithms is the map containing
+	// all the registered compression algorithms
+	// by their identifiers.
+	compressionAlgorithms = map[CompressionAlgorithm]*compressionAlgorithmEntry{
+		CompressionAlgorithmLZWOrderLSBLitWidth8: {
+			name:           "LZW-LSB-8",
+			compress:       CompressLZWOrderLSBLitWidth8,
+			decompress:     DecompressLZWOrderLSBLitWidth8,
+			newWriter:      newLZWOrderLSBLitWidth8Writer,
+			newReader:      newLZWOrderLSBLitWidth8Reader,
+			decompressCost: 4.5,
+		},
+		CompressionAlgorithmDeflate: {
+			name:           "DEFLATE",
+			compress:       CompressDeflate,
+			compressLevel:  CompressDeflateLevel,
+			decompress:     DecompressDeflate,
+			newWriter:      newDeflateWriter,
+			newReader:      newDeflateReader,
+			decompressCost: 3,
+		},
+		CompressionAlgorithmZlib: {
+			name:           "zlib",
+			compress:       CompressZlib,
+			compressLevel:  CompressZlibLevel,
+			decompress:     DecompressZlib,
+			newWriter:      newZlibWriter,
+			newReader:      newZlibReader,
+			decompressCost: 3.25,
+		},
+		CompressionAlgorithmGzip: {
+			name:           "gzip",
+			compress:       CompressGzip,
+			compressLevel:  CompressGzipLevel,
+			decompress:     DecompressGzip,
+			newWriter:      newGzipWriter,
+			newReader:      newGzipReader,
+			decompressCost: 3.25,
+		},
+		CompressionAlgorithmLZ4: {
+			name:           "LZ4",
+			compress:       CompressLZ4,
+			decompress:     DecompressLZ4,
+			blockOnly:      true,
+			decompressCost: 1,
+		},
+	}
 )
 
+// RegisterCompressionAlgorithm adds a new compression
+// algorithm to the registry so it can be used by codecs
+// and resolved from the serialized resources. Both the
+// identifier and the name must be unique.
+func RegisterCompressionAlgorithm(
+	id CompressionAlgorithm, name string,
+	compress CompressFunc, decompress DecompressFunc,
+) error {
+	if name == "" {
+		return fmt.Errorf(
+			"compression algorithm %d has no name", id)
+	}
+
+	if compress == nil || decompress == nil {
+		return fmt.Errorf(
+			"compression algorithm '%s' lacks compression or decompression function",
+			name)
+	}
+
+	compressionAlgorithmsMutex.Lock()
+	defer compressionAlgorithmsMutex.Unlock()
+
+	if entry, ok := compressionAlgorithms[id]; ok {
+		return fmt.Errorf(
+			"compression algorithm %d is already registered as '%s'",
+			id, entry.name)
+	}
+
+	for registeredID, entry := range compressionAlgorithms {
+		if entry.name == name {
+			return fmt.Errorf(
+				"compression algorithm name '%s' is already taken by %d",
+				name, registeredID)
+		}
+	}
+
+	compressionAlgorithms[id] = &compressionAlgorithmEntry{
+		name:       name,
+		compress:   compress,
+		decompress: decompress,
+	}
+
+	return nil
+}
+
+// unregisterCompressionAlgorithm removes the
+// compression algorithm from the registry.
+func unregisterCompressionAlgorithm(id CompressionAlgorithm) {
+	compressionAlgorithmsMutex.Lock()
+	defer compressionAlgorithmsMutex.Unlock()
+
+	delete(compressionAlgorithms, id)
+}
+
+// RegisterCompressionLevels makes the registered
+// compression algorithm support compression levels.
+func RegisterCompressionLevels(id CompressionAlgorithm, compressLevel CompressLevelFunc) error {
+	if compressLevel == nil {
+		return fmt.Errorf(
+			"no leveled compression function for compression algorithm %d", id)
+	}
+
+	compressionAlgorithmsMutex.Lock()
+	defer compressionAlgorithmsMutex.Unlock()
+
+	entry, ok := compressionAlgorithms[id]
+
+	if !ok {
+		return fmt.Errorf(
+			"compression algorithm %d not found", id)
+	}
+
+	if entry.compressLevel != nil {
+		return fmt.Errorf(
+			"compression algorithm '%s' already supports compression levels",
+			entry.name)
+	}
+
+	// Entries are never mutated in place
+	// because lookups read them unguarded.
+	leveledEntry := *entry
+	leveledEntry.compressLevel = compressLevel
+	compressionAlgorithms[id] = &leveledEntry
+
+	return nil
+}
+
+// RegisterDecompressionCost records the decompression
+// time of the registered compression algorithm relative
+// to LZ4 for the speed-weighted selection. Algorithms
+// without the recorded cost are ranked as the slowest.
+func RegisterDecompressionCost(id CompressionAlgorithm, cost float64) error {
+	if cost <= 0 {
+		return fmt.Errorf(
+			"invalid decompression cost %f for compression algorithm %d",
+			cost, id)
+	}
+
+	compressionAlgorithmsMutex.Lock()
+	defer compressionAlgorithmsMutex.Unlock()
+
+	entry, ok := compressionAlgorithms[id]
+
+	if !ok {
+		return fmt.Errorf(
+			"compression algorithm %d not found", id)
+	}
+
+	costedEntry := *entry
+	costedEntry.decompressCost = cost
+	compressionAlgorithms[id] = &costedEntry
+
+	return nil
+}
+
+// LookupCompressionAlgorithm returns the compression and
+// decompression functions of the registered algorithm.
+func LookupCompressionAlgorithm(id CompressionAlgorithm) (CompressFunc, DecompressFunc, bool) {
+	entry, ok := lookupCompressionAlgorithm(id)
+
+	if !ok {
+		return nil, nil, false
+	}
+
+	return entry.compress, entry.decompress, true
+}
+
+// CompressionAlgorithmByName returns the identifier
+// of the registered compression algorithm with the
+// given name.
+func CompressionAlgorithmByName(name string) (CompressionAlgorithm, bool) {
+	compressionAlgorithmsMutex.RLock()
+	defer compressionAlgorithmsMutex.RUnlock()
+
+	for id, entry := range compressionAlgorithms {
+		if entry.name == name {
+			return id, true
+		}
+	}
+
+	return 0, false
+}
+
+// CompressionAlgorithms returns the identifiers of
+// all the registered compression algorithms
+// in ascending order.
+func CompressionAlgorithms() []CompressionAlgorithm {
+	compressionAlgorithmsMutex.RLock()
+	defer compressionAlgorithmsMutex.RUnlock()
+
+	ids := make([]CompressionAlgorithm, 0, len(compressionAlgorithms))
+
+	for id := range compressionAlgorithms {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	return ids
+}
+
+// lookupCompressionAlgorithm returns the
+// registry record of the compression algorithm.
+func lookupCompressionAlgorithm(id CompressionAlgorithm) (*compressionAlgorithmEntry, bool) {
+	compressionAlgorithmsMutex.RLock()
+	defer compressionAlgorithmsMutex.RUnlock()
+
+	entry, ok := compressionAlgorithms[id]
+
+	return entry, ok
+}
+
 // String returns the name of the compression algorithm.
 func (alg CompressionAlgorithm) String() string {
-	switch alg {
-	case CompressionAlgorithmLZWOrderLSBLitWidth8:
-		return "LZW-LSB-8"
+	entry, ok := lookupCompressionAlgorithm(alg)
 
-	default:
+	if !ok {
 		return ""
 	}
+
+	return entry.name
 }
@@ -0,0 +1,163 @@
+package codec
+
+import "fmt"
+
+// CompressBest compresses the given data with every
+// registered compression algorithm and returns
+// the smallest output along with the algorithm
+// that produced it.
+func CompressBest(in []byte) ([]byte, CompressionAlgorithm, error) {
+	return DefaultCodec().CompressBest(in)
+}
+
+// CompressBestWeighted compresses the given data with
+// every registered compression algorithm and returns
+// the output with the best size/speed score.
+//
+// speedWeight lies in [0; 1]: 0 picks the smallest output
+// while 1 picks the output decompressing the fastest.
+func CompressBestWeighted(in []byte, speedWeight float64) ([]byte, CompressionAlgorithm, error) {
+	return DefaultCodec().CompressBestWeighted(in, speedWeight)
+}
+
+// CompressBest compresses the given data with every
+// registered compression algorithm at the compression
+// level of the codec and returns the smallest output.
+func (c *Codec) CompressBest(in []byte) ([]byte, CompressionAlgorithm, error) {
+	return c.CompressBestWeighted(in, 0)
+}
+
+// CompressBestWeighted compresses the given data with
+// every registered compression algorithm at the
+// compression level of the codec and returns
+// the output with the best size/speed score.
+//
+// The size is normalized by the smallest output and
+// the speed by the cheapest fixed decompression cost
+// among the algorithms so the selection is repeatable.
+// The algorithms failing to compress the data are
+// skipped. Ties are resolved in favour of the lesser
+// identifier.
+func (c *Codec) CompressBestWeighted(in []byte, speedWeight float64) ([]byte, CompressionAlgorithm, error) {
+	if speedWeight < 0 || speedWeight > 1 {
+		return nil, 0, fmt.Errorf(
+			"speed weight %f is out of range [0; 1]", speedWeight)
+	}
+
+	type candidate struct {
+		algorithm      CompressionAlgorithm
+		compressedData []byte
+		decompressCost float64
+	}
+
+	algorithmCodec := *c
+	candidates := []candidate{}
+	var lastErr error
+
+	for _, algorithm := range CompressionAlgorithms() {
+		entry, ok := lookupCompressionAlgorithm(algorithm)
+
+		if !ok {
+			continue
+		}
+
+		algorithmCodec.CompressionAlgorithm = algorithm
+		compressedData, err := algorithmCodec.Compress(in)
+
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", algorithm, err)
+			continue
+		}
+
+		candidates = append(candidates, candidate{
+			algorithm:      algorithm,
+			compressedData: compressedData,
+			decompressCost: entry.decompressCost,
+		})
+	}
+
+	if len(candidates) == 0 {
+		if lastErr != nil {
+			return nil, 0, fmt.Errorf(
+				"no compression algorithm succeeded: %w", lastErr)
+		}
+
+		return nil, 0, fmt.Errorf(
+			"no compression algorithms registered")
+	}
+
+	minSize := len(candidates[0].compressedData)
+	minCost, maxCost := 0.0, 0.0
+
+	for _, cand := range candidates {
+		minSize = min(minSize, len(cand.compressedData))
+
+		if cand.decompressCost > 0 && (minCost == 0 || cand.decompressCost < minCost) {
+			minCost = cand.decompressCost
+		}
+
+		maxCost = max(maxCost, cand.decompressCost)
+	}
+
+	score := func(cand candidate) float64 {
+		sizeScore := float64(len(cand.compressedData)) / float64(max(minSize, 1))
+
+		if speedWeight == 0 || minCost == 0 {
+			return sizeScore
+		}
+
+		// The algorithms of unknown cost
+		// are ranked as the slowest ones.
+		cost := cand.decompressCost
+
+		if cost == 0 {
+			cost = maxCost
+		}
+
+		speedScore := cost / minCost
+
+		return (1-speedWeight)*sizeScore + speedWeight*speedScore
+	}
+
+	best := candidates[0]
+	bestScore := score(best)
+
+	for _, cand := range candidates[1:] {
+		if candScore := score(cand); candScore < bestScore {
+			best = cand
+			bestScore = candScore
+		}
+	}
+
+	return best.compressedData, best.algorithm, nil
+}
+
+// CompressPictureBest compresses the pixels of the picture
+// with every registered compression algorithm and keeps
+// the output with the best size/speed score.
+//
+// If the chunk size of the codec is set, the algorithm
+// is chosen by the whole pixels and then used to
+// compress every chunk as CompressPicture does.
+func (c *Codec) CompressPictureBest(picture *PictureData, speedWeight float64) (*CompressedPictureData, error) {
+	filteredPix, err := c.filterPicturePix(picture)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compressedPix, algorithm, err := c.CompressBestWeighted(filteredPix, speedWeight)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bestCodec := *c
+	bestCodec.CompressionAlgorithm = algorithm
+
+	if bestCodec.ChunkSize > 0 {
+		return bestCodec.compressFilteredPix(picture, filteredPix)
+	}
+
+	return bestCodec.newCompressedPicture(picture, compressedPix), nil
+}
@@ -2,30 +2,18 @@ package codec
 
 import (
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
 	"compress/lzw"
+	"compress/zlib"
 	"fmt"
-)
-
-var (
-	// compression is the map containing all the
-	// available compression functions by their identifiers.
-	compression = map[CompressionAlgorithm]func([]byte) ([]byte, error){
-		CompressionAlgorithmLZWOrderLSBLitWidth8: CompressLZWOrderLSBLitWidth8,
-	}
+	"io"
 )
 
 // Compress compresses the given data using
 // the consented compression function.
 func Compress(in []byte) ([]byte, error) {
-	compressionAlgorithm, ok := compression[CompressionAlgorithmLZWOrderLSBLitWidth8]
-
-	if !ok {
-		return nil, fmt.Errorf(
-			"compression algorithm '%s' not found",
-			ConsentedCompressionAlgorithm)
-	}
-
-	return compressionAlgorithm(in)
+	return DefaultCodec().Compress(in)
 }
 
 // CompressLZWOrderLSBLitWidth8 uses the
@@ -34,21 +22,41 @@ func Compress(in []byte) ([]byte, error) {
 func CompressLZWOrderLSBLitWidth8(in []byte) ([]byte, error) {
 	var buffer bytes.Buffer
 	writer := lzw.NewWriter(&buffer, lzw.LSB, 8)
-	total := 0
-	var written int
-	var err error
 
-	for written, err = writer.Write(in[total:]); written > 0 && err == nil; written, err = writer.Write(in[total:]) {
-		total += written
+	err := writeCompressed(writer, in)
+
+	if err != nil {
+		return nil, err
 	}
 
+	return buffer.Bytes(), nil
+}
+
+// CompressDeflate uses the DEFLATE compression
+// algorithm with the default compression level
+// to compress the given data.
+func CompressDeflate(in []byte) ([]byte, error) {
+	return CompressDeflateLevel(in, CompressionLevelDefault)
+}
+
+// CompressDeflateLevel uses the DEFLATE compression
+// algorithm with the specified compression level
+// to compress the given data.
+func CompressDeflateLevel(in []byte, level int) ([]byte, error) {
+	flateLevel, err := flateCompressionLevel(level)
+
 	if err != nil {
 		return nil, err
 	}
 
-	// It seems it's not okay to
-	// defer the Close() call here.
-	err = writer.Close()
+	var buffer bytes.Buffer
+	writer, err := flate.NewWriter(&buffer, flateLevel)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeCompressed(writer, in)
 
 	if err != nil {
 		return nil, err
@@ -56,3 +64,115 @@ func CompressLZWOrderLSBLitWidth8(in []byte) ([]byte, error) {
 
 	return buffer.Bytes(), nil
 }
+
+// CompressZlib uses the zlib compression
+// algorithm with the default compression level
+// to compress the given data.
+func CompressZlib(in []byte) ([]byte, error) {
+	return CompressZlibLevel(in, CompressionLevelDefault)
+}
+
+// CompressZlibLevel uses the zlib compression
+// algorithm with the specified compression level
+// to compress the given data.
+func CompressZlibLevel(in []byte, level int) ([]byte, error) {
+	flateLevel, err := flateCompressionLevel(level)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	writer, err := zlib.NewWriterLevel(&buffer, flateLevel)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeCompressed(writer, in)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// CompressGzip uses the gzip compression
+// algorithm with the default compression level
+// to compress the given data.
+func CompressGzip(in []byte) ([]byte, error) {
+	return CompressGzipLevel(in, CompressionLevelDefault)
+}
+
+// CompressGzipLevel uses the gzip compression
+// algorithm with the specified compression level
+// to compress the given data.
+func CompressGzipLevel(in []byte, level int) ([]byte, error) {
+	flateLevel, err := flateCompressionLevel(level)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&buffer, flateLevel)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeCompressed(writer, in)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// CompressLZ4 uses the LZ4 block
+// compression algorithm to compress
+// the given data.
+func CompressLZ4(in []byte) ([]byte, error) {
+	return lz4CompressBlock(in), nil
+}
+
+// flateCompressionLevel converts the codec
+// compression level to the one of the
+// compress/flate package.
+func flateCompressionLevel(level int) (int, error) {
+	if level == CompressionLevelDefault {
+		return flate.DefaultCompression, nil
+	}
+
+	if level < CompressionLevelBestSpeed ||
+		level > CompressionLevelBestCompression {
+		return 0, fmt.Errorf(
+			"compression level %d is out of range [%d; %d]",
+			level, CompressionLevelBestSpeed,
+			CompressionLevelBestCompression)
+	}
+
+	return level, nil
+}
+
+// writeCompressed writes all the data
+// to the compressing writer and closes it.
+func writeCompressed(writer io.WriteCloser, in []byte) error {
+	total := 0
+	var written int
+	var err error
+
+	for written, err = writer.Write(in[total:]); written > 0 && err == nil; written, err = writer.Write(in[total:]) {
+		total += written
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// It seems it's not okay to
+	// defer the Close() call here.
+	return writer.Close()
+}
@@ -5,6 +5,7 @@ import (
 	_ "embed"
 	"image"
 	"image/draw"
+	"io"
 	"testing"
 
 	codec "github.com/alacrity-engine/resource-codec"
@@ -61,7 +62,346 @@ func TestCompressPicture(t *testing.T) {
 
 	compressedPicture, err := picture.Compress()
 	assert.Nil(t, err)
-	assert.Equal(t, 7155, len(compressedPicture.CompressedPix))
+	assert.Equal(t, 6507877, len(compressedPicture.CompressedPix))
 	assert.Equal(t, codec.HashAlgorithmKeccak256, compressedPicture.OriginalHashAlgorithm)
 	assert.Equal(t, codec.CompressionAlgorithmLZWOrderLSBLitWidth8, compressedPicture.CompressionAlgorithm)
 }
+
+func TestRegisterCompressionAlgorithm(t *testing.T) {
+	const identity codec.CompressionAlgorithm = 1000
+
+	err := codec.RegisterCompressionAlgorithm(identity, "identity",
+		func(in []byte) ([]byte, error) {
+			return append([]byte{}, in...), nil
+		},
+		func(in []byte, sourceSize int) ([]byte, error) {
+			return append([]byte{}, in...), nil
+		})
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		codec.UnregisterCompressionAlgorithm(identity)
+	})
+	assert.Equal(t, "identity", identity.String())
+	assert.Contains(t, codec.CompressionAlgorithms(), identity)
+
+	id, ok := codec.CompressionAlgorithmByName("identity")
+	assert.True(t, ok)
+	assert.Equal(t, identity, id)
+
+	compressedData, err := codec.NewCodec(codec.HashAlgorithmSHA256,
+		codec.PixFormatRGBA, identity).Compress([]byte("cirno"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("cirno"), compressedData)
+
+	err = codec.RegisterCompressionAlgorithm(identity, "identity-2",
+		codec.CompressLZWOrderLSBLitWidth8, codec.DecompressLZWOrderLSBLitWidth8)
+	assert.NotNil(t, err)
+	err = codec.RegisterCompressionAlgorithm(identity+1, "LZW-LSB-8",
+		codec.CompressLZWOrderLSBLitWidth8, codec.DecompressLZWOrderLSBLitWidth8)
+	assert.NotNil(t, err)
+}
+
+func TestCompressionAlgorithmsRoundTrip(t *testing.T) {
+	imgRGBA := testImageRGBA(t)
+
+	algorithms := []codec.CompressionAlgorithm{
+		codec.CompressionAlgorithmLZWOrderLSBLitWidth8,
+		codec.CompressionAlgorithmDeflate,
+		codec.CompressionAlgorithmZlib,
+		codec.CompressionAlgorithmGzip,
+		codec.CompressionAlgorithmLZ4,
+	}
+	levels := []int{
+		codec.CompressionLevelDefault,
+		codec.CompressionLevelBestSpeed,
+		codec.CompressionLevelBestCompression,
+	}
+
+	for _, algorithm := range algorithms {
+		for _, level := range levels {
+			c := codec.NewCodec(codec.HashAlgorithmSHA256,
+				codec.PixFormatRGBA, algorithm)
+			c.CompressionLevel = level
+
+			compressedPix, err := c.Compress(imgRGBA.Pix)
+			assert.Nil(t, err, algorithm.String())
+			assert.Less(t, len(compressedPix), len(imgRGBA.Pix), algorithm.String())
+
+			decompressedPix, err := c.Decompress(compressedPix, len(imgRGBA.Pix))
+			assert.Nil(t, err, algorithm.String())
+			assert.Equal(t, imgRGBA.Pix, decompressedPix, algorithm.String())
+		}
+	}
+}
+
+func TestCompressDeflateLevelOutOfRange(t *testing.T) {
+	_, err := codec.CompressDeflateLevel([]byte("cirno"), 10)
+	assert.NotNil(t, err)
+}
+
+func TestLZ4RoundTrip(t *testing.T) {
+	imgRGBA := testImageRGBA(t)
+
+	inputs := [][]byte{
+		{},
+		[]byte("cirno"),
+		bytes.Repeat([]byte{9}, 1<<16+100),
+		bytes.Repeat([]byte("baka"), 1000),
+		imgRGBA.Pix,
+	}
+
+	for _, input := range inputs {
+		compressedData, err := codec.CompressLZ4(input)
+		assert.Nil(t, err)
+
+		decompressedData, err := codec.DecompressLZ4(compressedData, len(input))
+		assert.Nil(t, err)
+		assert.Equal(t, input, decompressedData)
+	}
+
+	// The block expanding into a megabyte
+	// is rejected before it's expanded.
+	bomb := append([]byte{0x1F, 'a', 0x01, 0x00},
+		bytes.Repeat([]byte{0xFF}, 4096)...)
+	bomb = append(bomb, 0x00)
+	_, err := codec.DecompressLZ4(bomb, 16)
+	assert.ErrorIs(t, err, codec.ErrTrailingData)
+
+	_, err = codec.DecompressLZ4(bomb, -4)
+	assert.NotNil(t, err)
+}
+
+func BenchmarkDecompressLZ4(b *testing.B) {
+	benchmarkDecompress(b, codec.CompressLZ4, codec.DecompressLZ4)
+}
+
+func BenchmarkDecompressLZWOrderLSBLitWidth8(b *testing.B) {
+	benchmarkDecompress(b, codec.CompressLZWOrderLSBLitWidth8,
+		codec.DecompressLZWOrderLSBLitWidth8)
+}
+
+func benchmarkDecompress(b *testing.B, compress codec.CompressFunc, decompress codec.DecompressFunc) {
+	imgRGBA := testImageRGBA(b)
+
+	compressedPix, err := compress(imgRGBA.Pix)
+	assert.Nil(b, err)
+
+	b.SetBytes(int64(len(imgRGBA.Pix)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := decompress(compressedPix, len(imgRGBA.Pix))
+
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCompressionStreamsRoundTrip(t *testing.T) {
+	imgRGBA := testImageRGBA(t)
+
+	for _, algorithm := range codec.CompressionAlgorithms() {
+		var compressed bytes.Buffer
+		writer, err := codec.NewCompressWriter(&compressed, algorithm)
+
+		if err != nil {
+			// Not every registered algorithm supports streaming.
+			continue
+		}
+
+		_, err = io.Copy(writer, bytes.NewReader(imgRGBA.Pix))
+		assert.Nil(t, err, algorithm.String())
+		assert.Nil(t, writer.Close(), algorithm.String())
+
+		// The streamed output must be
+		// compatible with the buffered one.
+		decompressedPix, err := codec.NewCodec(codec.HashAlgorithmSHA256,
+			codec.PixFormatRGBA, algorithm).Decompress(compressed.Bytes(), len(imgRGBA.Pix))
+		assert.Nil(t, err, algorithm.String())
+		assert.Equal(t, imgRGBA.Pix, decompressedPix, algorithm.String())
+
+		reader, err := codec.NewDecompressReader(&compressed, algorithm)
+		assert.Nil(t, err, algorithm.String())
+		decompressedPix, err = io.ReadAll(reader)
+		assert.Nil(t, err, algorithm.String())
+		assert.Nil(t, reader.Close(), algorithm.String())
+		assert.Equal(t, imgRGBA.Pix, decompressedPix, algorithm.String())
+	}
+
+	// The LZ4 block format has no framing
+	// so it cannot be streamed at all.
+	_, err := codec.NewCompressWriter(io.Discard, codec.CompressionAlgorithmLZ4)
+	assert.NotNil(t, err)
+	err = codec.RegisterCompressionStreams(codec.CompressionAlgorithmLZ4,
+		func(w io.Writer, level int) (io.WriteCloser, error) {
+			return nil, nil
+		},
+		func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(r), nil
+		})
+	assert.NotNil(t, err)
+}
+
+func TestCompressBest(t *testing.T) {
+	imgRGBA := testImageRGBA(t)
+
+	compressedPix, algorithm, err := codec.CompressBest(imgRGBA.Pix)
+	assert.Nil(t, err)
+
+	for _, other := range codec.CompressionAlgorithms() {
+		otherPix, err := codec.NewCodec(codec.HashAlgorithmSHA256,
+			codec.PixFormatRGBA, other).Compress(imgRGBA.Pix)
+		assert.Nil(t, err)
+		assert.LessOrEqual(t, len(compressedPix), len(otherPix), other.String())
+	}
+
+	decompressedPix, err := codec.NewCodec(codec.HashAlgorithmSHA256,
+		codec.PixFormatRGBA, algorithm).Decompress(compressedPix, len(imgRGBA.Pix))
+	assert.Nil(t, err)
+	assert.Equal(t, imgRGBA.Pix, decompressedPix)
+
+	// The speed-only selection is repeatable
+	// and picks the cheapest decompression.
+	for i := 0; i < 2; i++ {
+		_, algorithm, err = codec.CompressBestWeighted(imgRGBA.Pix, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, codec.CompressionAlgorithmLZ4, algorithm)
+	}
+
+	// The failing algorithms are skipped.
+	const failing codec.CompressionAlgorithm = 1001
+	err = codec.RegisterCompressionAlgorithm(failing, "failing",
+		func(in []byte) ([]byte, error) {
+			return nil, io.ErrUnexpectedEOF
+		}, codec.DecompressLZ4)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		codec.UnregisterCompressionAlgorithm(failing)
+	})
+	_, _, err = codec.CompressBest(imgRGBA.Pix)
+	assert.Nil(t, err)
+	_, _, err = codec.CompressBestWeighted(imgRGBA.Pix, 1.5)
+	assert.NotNil(t, err)
+}
+
+func TestCompressPictureBest(t *testing.T) {
+	pix := make([]byte, 64*48*4)
+
+	for i := range pix {
+		pix[i] = byte(i / 7)
+	}
+
+	hash, err := codec.Hash(pix)
+	assert.Nil(t, err)
+	picture := &codec.PictureData{
+		Width:         64,
+		Height:        48,
+		Pix:           pix,
+		Hash:          hash,
+		PixFormat:     codec.PixFormatRGBA,
+		HashAlgorithm: codec.ConsentedHashAlgorithm,
+	}
+
+	// The chunk size of the codec is honoured.
+	c := codec.DefaultCodec()
+	c.ChunkSize = 1000
+	compressedPicture, err := c.CompressPictureBest(picture, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(c.ChunkSize), compressedPicture.ChunkSize)
+	assert.Len(t, compressedPicture.CompressedChunkSizes, (len(pix)+999)/1000)
+
+	decompressedPicture, err := compressedPicture.Decompress()
+	assert.Nil(t, err)
+	assert.Equal(t, pix, decompressedPicture.Pix)
+
+	// The pixels not matching the size are rejected.
+	picture.Pix = pix[:len(pix)-1]
+	_, err = c.CompressPictureBest(picture, 0)
+	assert.NotNil(t, err)
+}
+
+func TestDecompressCorruptedData(t *testing.T) {
+	imgRGBA := testImageRGBA(t)
+
+	for _, algorithm := range []codec.CompressionAlgorithm{
+		codec.CompressionAlgorithmLZWOrderLSBLitWidth8,
+		codec.CompressionAlgorithmDeflate,
+		codec.CompressionAlgorithmZlib,
+		codec.CompressionAlgorithmGzip,
+		codec.CompressionAlgorithmLZ4,
+	} {
+		c := codec.NewCodec(codec.HashAlgorithmSHA256,
+			codec.PixFormatRGBA, algorithm)
+		compressedPix, err := c.Compress(imgRGBA.Pix)
+		assert.Nil(t, err)
+
+		// Truncated input.
+		_, err = c.Decompress(compressedPix[:len(compressedPix)/2], len(imgRGBA.Pix))
+		assert.ErrorIs(t, err, codec.ErrTruncated, algorithm.String())
+
+		// Overflowing output.
+		_, err = c.Decompress(compressedPix, len(imgRGBA.Pix)-1)
+		assert.ErrorIs(t, err, codec.ErrTrailingData, algorithm.String())
+
+		// Malformed input.
+		garbage := append([]byte{0x1F, 0x00, 0x00, 0x00},
+			bytes.Repeat([]byte{0xFF}, 60)...)
+		_, err = c.Decompress(garbage, len(imgRGBA.Pix))
+		assert.NotNil(t, err, algorithm.String())
+		assert.NotErrorIs(t, err, codec.ErrTruncated, algorithm.String())
+		assert.NotErrorIs(t, err, codec.ErrTrailingData, algorithm.String())
+
+		// Negative source size.
+		_, err = c.Decompress(compressedPix, -4)
+		assert.NotNil(t, err, algorithm.String())
+	}
+
+	// Negative sizes of the picture.
+	picture := &codec.PictureData{
+		Width:  2,
+		Height: 1,
+		Pix:    []byte{255, 0, 0, 255, 0, 0, 255, 128},
+	}
+	hash, err := codec.Hash(picture.Pix)
+	assert.Nil(t, err)
+	picture.Hash = hash
+	c := codec.DefaultCodec()
+	c.PixFilter = codec.PixFilterAdaptive
+	compressedPicture, err := c.CompressPicture(picture)
+	assert.Nil(t, err)
+
+	for _, corrupt := range []func(*codec.CompressedPictureData){
+		func(p *codec.CompressedPictureData) { p.OriginalPixSize = -4 },
+		func(p *codec.CompressedPictureData) { p.Width = -2 },
+		func(p *codec.CompressedPictureData) { p.Height = -100 },
+	} {
+		corruptedPicture := *compressedPicture
+		corrupt(&corruptedPicture)
+		_, err = corruptedPicture.Decompress()
+		assert.NotNil(t, err)
+	}
+}
+
+func TestCompressPictureParallel(t *testing.T) {
+	picture := testPictureRGBA(t)
+
+	const chunkSize = 1 << 20
+	singleWorkerPicture, err := picture.CompressParallel(chunkSize, 1)
+	assert.Nil(t, err)
+	multiWorkerPicture, err := picture.CompressParallel(chunkSize, 8)
+	assert.Nil(t, err)
+	assert.Equal(t, singleWorkerPicture.CompressedPix, multiWorkerPicture.CompressedPix)
+	assert.Equal(t, singleWorkerPicture.CompressedChunkSizes, multiWorkerPicture.CompressedChunkSizes)
+	assert.Len(t, multiWorkerPicture.CompressedChunkSizes,
+		(len(picture.Pix)+chunkSize-1)/chunkSize)
+
+	data, err := multiWorkerPicture.ToBytes()
+	assert.Nil(t, err)
+	deserializedPicture, err := codec.CompressedPictureFromBytes(data)
+	assert.Nil(t, err)
+
+	decompressedPicture, err := deserializedPicture.Decompress()
+	assert.Nil(t, err)
+	assert.Equal(t, picture.Pix, decompressedPicture.Pix)
+}
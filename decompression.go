@@ -2,31 +2,28 @@ package codec
 
 import (
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
 	"compress/lzw"
+	"compress/zlib"
+	"errors"
 	"fmt"
 	"io"
 )
 
 var (
-	// decompression is the map containing all the
-	// available decompression functions by their identifiers.
-	decompression = map[CompressionAlgorithm]func([]byte, int) ([]byte, error){
-		CompressionAlgorithmLZWOrderLSBLitWidth8: DecompressLZWOrderLSBLitWidth8,
-	}
+	// ErrTruncated is returned when the decompressed
+	// data is shorter than its recorded source size.
+	ErrTruncated = errors.New("decompressed data is truncated")
+	// ErrTrailingData is returned when the decompressed
+	// data is longer than its recorded source size.
+	ErrTrailingData = errors.New("decompressed data has trailing bytes")
 )
 
 // Decompress decompresses the given data using
 // the consented decompression function.
 func Decompress(in []byte, sourceSize int) ([]byte, error) {
-	decompressionAlgorithm, ok := decompression[ConsentedCompressionAlgorithm]
-
-	if !ok {
-		return nil, fmt.Errorf(
-			"decompression algorithm '%s' not found",
-			ConsentedCompressionAlgorithm)
-	}
-
-	return decompressionAlgorithm(in, sourceSize)
+	return DefaultCodec().Decompress(in, sourceSize)
 }
 
 // DecompressLZWOrderLSBLitWidth8 uses the
@@ -34,17 +31,112 @@ func Decompress(in []byte, sourceSize int) ([]byte, error) {
 // to decompress the given data.
 func DecompressLZWOrderLSBLitWidth8(in []byte, sourceSize int) ([]byte, error) {
 	buffer := bytes.NewReader(in)
-	source := make([]byte, sourceSize)
-
 	reader := lzw.NewReader(buffer, lzw.LSB, 8)
 	defer reader.Close()
-	total := 0
-	read := 1
-	var err error
 
-	for err != io.EOF && read > 0 {
-		read, err = reader.Read(source[total:])
-		total += read
+	return readDecompressed(reader, sourceSize)
+}
+
+// DecompressDeflate uses the DEFLATE
+// decompression algorithm to decompress
+// the given data.
+func DecompressDeflate(in []byte, sourceSize int) ([]byte, error) {
+	buffer := bytes.NewReader(in)
+	reader := flate.NewReader(buffer)
+	defer reader.Close()
+
+	return readDecompressed(reader, sourceSize)
+}
+
+// DecompressZlib uses the zlib
+// decompression algorithm to decompress
+// the given data.
+func DecompressZlib(in []byte, sourceSize int) ([]byte, error) {
+	buffer := bytes.NewReader(in)
+	reader, err := zlib.NewReader(buffer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	return readDecompressed(reader, sourceSize)
+}
+
+// DecompressGzip uses the gzip
+// decompression algorithm to decompress
+// the given data.
+func DecompressGzip(in []byte, sourceSize int) ([]byte, error) {
+	buffer := bytes.NewReader(in)
+	reader, err := gzip.NewReader(buffer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	return readDecompressed(reader, sourceSize)
+}
+
+// DecompressLZ4 uses the LZ4 block
+// decompression algorithm to decompress
+// the given data.
+func DecompressLZ4(in []byte, sourceSize int) ([]byte, error) {
+	if sourceSize < 0 {
+		return nil, fmt.Errorf(
+			"invalid source size %d", sourceSize)
+	}
+
+	decompressed, err := lz4DecompressBlock(in,
+		make([]byte, 0, sourceSize), sourceSize)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(decompressed) < sourceSize {
+		return nil, fmt.Errorf("%w: got %d of %d bytes",
+			ErrTruncated, len(decompressed), sourceSize)
+	}
+
+	return decompressed, nil
+}
+
+// readDecompressed reads exactly sourceSize
+// bytes out of the decompressing reader and
+// makes sure the stream ends right after them.
+func readDecompressed(reader io.Reader, sourceSize int) ([]byte, error) {
+	if sourceSize < 0 {
+		return nil, fmt.Errorf(
+			"invalid source size %d", sourceSize)
+	}
+
+	source := make([]byte, sourceSize)
+	read, err := io.ReadFull(reader, source)
+
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("%w: got %d of %d bytes",
+				ErrTruncated, read, sourceSize)
+		}
+
+		return nil, err
+	}
+
+	// Reading past the end also makes the
+	// decompressor verify its checksum if any.
+	var probe [1]byte
+	read, err = io.ReadFull(reader, probe[:])
+
+	if read > 0 {
+		return nil, fmt.Errorf("%w: more than %d bytes",
+			ErrTrailingData, sourceSize)
+	}
+
+	if err != io.EOF {
+		return nil, err
 	}
 
 	return source, nil
@@ -0,0 +1,258 @@
+package codec
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrTamperedResource is returned when the encrypted
+	// resource fails the authentication.
+	ErrTamperedResource = errors.New("encrypted resource is tampered")
+	// ErrUnknownEncryptionKey is returned when the keyring
+	// has no key the resource was encrypted with.
+	ErrUnknownEncryptionKey = errors.New("unknown encryption key")
+)
+
+// Serializable is any resource that
+// can be converted to a byte array.
+type Serializable interface {
+	ToBytes() ([]byte, error)
+}
+
+// EncryptionKeyring is a thread-safe set of the
+// AES keys by their identifiers.
+type EncryptionKeyring struct {
+	mutex sync.RWMutex
+	keys  map[string][]byte
+}
+
+// NewEncryptionKeyring creates
+// a new empty encryption keyring.
+func NewEncryptionKeyring() *EncryptionKeyring {
+	return &EncryptionKeyring{
+		keys: map[string][]byte{},
+	}
+}
+
+// AddKey adds the AES-128, AES-192 or AES-256
+// key to the keyring under the identifier.
+func (keyring *EncryptionKeyring) AddKey(keyID string, key []byte) error {
+	_, err := aes.NewCipher(key)
+
+	if err != nil {
+		return fmt.Errorf("key '%s': %w", keyID, err)
+	}
+
+	keyring.mutex.Lock()
+	defer keyring.mutex.Unlock()
+
+	if _, ok := keyring.keys[keyID]; ok {
+		return fmt.Errorf(
+			"encryption key '%s' is already in the keyring", keyID)
+	}
+
+	keyring.keys[keyID] = append([]byte{}, key...)
+
+	return nil
+}
+
+// Key returns the encryption key
+// with the given identifier.
+func (keyring *EncryptionKeyring) Key(keyID string) ([]byte, bool) {
+	keyring.mutex.RLock()
+	defer keyring.mutex.RUnlock()
+
+	key, ok := keyring.keys[keyID]
+
+	return key, ok
+}
+
+// EncryptedResourceData is the AES-GCM envelope
+// of a serialized resource. The key identifier
+// is authenticated along with the ciphertext.
+type EncryptedResourceData struct {
+	KeyID      string
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// EncryptResource seals the serialized resource
+// in the AES-GCM envelope with a random nonce.
+func EncryptResource(data []byte, keyID string, key []byte) (*EncryptedResourceData, error) {
+	aead, err := newResourceAEAD(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedResourceData{
+		KeyID:      keyID,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, data, []byte(keyID)),
+	}, nil
+}
+
+// ToEncryptedBytes serializes the resource and
+// seals it in the AES-GCM envelope.
+func ToEncryptedBytes(resource Serializable, keyID string, key []byte) ([]byte, error) {
+	data, err := resource.ToBytes()
+
+	if err != nil {
+		return nil, err
+	}
+
+	erd, err := EncryptResource(data, keyID, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return erd.ToBytes()
+}
+
+// FromEncryptedBytes opens the AES-GCM envelope with
+// the key from the keyring and restores the resource
+// with the given deserialization function, e.g.
+// CompressedPictureFromBytes.
+func FromEncryptedBytes[T any](
+	data []byte, keyring *EncryptionKeyring,
+	fromBytes func([]byte) (T, error),
+) (T, error) {
+	var resource T
+	erd, err := EncryptedResourceFromBytes(data)
+
+	if err != nil {
+		return resource, err
+	}
+
+	plaintext, err := erd.Decrypt(keyring)
+
+	if err != nil {
+		return resource, err
+	}
+
+	return fromBytes(plaintext)
+}
+
+// Decrypt opens the envelope with the key from
+// the keyring and returns the serialized resource.
+func (erd *EncryptedResourceData) Decrypt(keyring *EncryptionKeyring) ([]byte, error) {
+	key, ok := keyring.Key(erd.KeyID)
+
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownEncryptionKey, erd.KeyID)
+	}
+
+	aead, err := newResourceAEAD(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(erd.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: nonce is %d bytes long",
+			ErrTamperedResource, len(erd.Nonce))
+	}
+
+	plaintext, err := aead.Open(nil, erd.Nonce, erd.Ciphertext, []byte(erd.KeyID))
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTamperedResource, err)
+	}
+
+	return plaintext, nil
+}
+
+// newResourceAEAD creates the AES-GCM
+// cipher out of the key.
+func newResourceAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func (erd *EncryptedResourceData) ToBytes() ([]byte, error) {
+	buffer := bytes.NewBuffer([]byte{})
+
+	err := binary.Write(buffer, binary.BigEndian, int32(len(erd.KeyID)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = buffer.WriteString(erd.KeyID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(buffer, binary.BigEndian, int32(len(erd.Nonce)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = buffer.Write(erd.Nonce)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(buffer, binary.BigEndian, int32(len(erd.Ciphertext)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = buffer.Write(erd.Ciphertext)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func EncryptedResourceFromBytes(data []byte) (*EncryptedResourceData, error) {
+	buffer := bytes.NewBuffer(data)
+	erd := &EncryptedResourceData{}
+
+	keyIDData, err := readLengthPrefixed(buffer)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTamperedResource, err)
+	}
+
+	erd.KeyID = string(keyIDData)
+	erd.Nonce, err = readLengthPrefixed(buffer)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTamperedResource, err)
+	}
+
+	erd.Ciphertext, err = readLengthPrefixed(buffer)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTamperedResource, err)
+	}
+
+	return erd, nil
+}
@@ -0,0 +1,41 @@
+package codec_test
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptResource(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(t, err)
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+	compressedPicture, err := picture.Compress()
+	assert.Nil(t, err)
+
+	key := bytes.Repeat([]byte{9}, 32)
+	keyring := codec.NewEncryptionKeyring()
+	assert.Nil(t, keyring.AddKey("premium", key))
+
+	data, err := codec.ToEncryptedBytes(compressedPicture, "premium", key)
+	assert.Nil(t, err)
+
+	restoredPicture, err := codec.FromEncryptedBytes(data, keyring,
+		codec.CompressedPictureFromBytes)
+	assert.Nil(t, err)
+	assert.Equal(t, compressedPicture.CompressedPix, restoredPicture.CompressedPix)
+	assert.Equal(t, compressedPicture.OriginalHash, restoredPicture.OriginalHash)
+
+	data[len(data)-1]++
+	_, err = codec.FromEncryptedBytes(data, keyring,
+		codec.CompressedPictureFromBytes)
+	assert.ErrorIs(t, err, codec.ErrTamperedResource)
+
+	_, err = codec.FromEncryptedBytes(data, codec.NewEncryptionKeyring(),
+		codec.CompressedPictureFromBytes)
+	assert.ErrorIs(t, err, codec.ErrUnknownEncryptionKey)
+}
@@ -0,0 +1,8 @@
+package codec
+
+// UnregisterCompressionAlgorithm and UnregisterHashAlgorithm
+// expose the registry cleanup to the tests.
+var (
+	UnregisterCompressionAlgorithm = unregisterCompressionAlgorithm
+	UnregisterHashAlgorithm        = unregisterHashAlgorithm
+)
@@ -0,0 +1,265 @@
+package codec
+
+import "fmt"
+
+// filteredPixSize returns the size of the pixel
+// data after the filter is applied to it.
+func filteredPixSize(pixSize, height int, filter PixFilter) int {
+	if filter == PixFilterAdaptive {
+		// Each row is prefixed with
+		// the byte of its filter.
+		return pixSize + height
+	}
+
+	return pixSize
+}
+
+// filterPix applies the filter to the pixel
+// data of the picture of the given size.
+func filterPix(pix []byte, width, height, bytesPerPixel int, filter PixFilter) ([]byte, error) {
+	if filter == PixFilterNone {
+		return pix, nil
+	}
+
+	stride := width * bytesPerPixel
+
+	if bytesPerPixel <= 0 || len(pix) != stride*height {
+		return nil, fmt.Errorf(
+			"pixel data of %d bytes doesn't match the %dx%d picture with %d bytes per pixel",
+			len(pix), width, height, bytesPerPixel)
+	}
+
+	switch filter {
+	case PixFilterPlanar:
+		return splitPlanes(pix, bytesPerPixel), nil
+
+	case PixFilterSub, PixFilterUp, PixFilterAverage, PixFilterPaeth:
+		filtered := make([]byte, len(pix))
+		prev := make([]byte, stride)
+
+		for y := 0; y < height; y++ {
+			row := pix[y*stride : (y+1)*stride]
+			filterRow(filter, filtered[y*stride:(y+1)*stride],
+				row, prev, bytesPerPixel)
+			prev = row
+		}
+
+		return filtered, nil
+
+	case PixFilterAdaptive:
+		filtered := make([]byte, 0, filteredPixSize(len(pix), height, filter))
+		candidate := make([]byte, stride)
+		best := make([]byte, stride)
+		prev := make([]byte, stride)
+
+		for y := 0; y < height; y++ {
+			row := pix[y*stride : (y+1)*stride]
+			bestFilter := PixFilterNone
+			copy(best, row)
+			bestCost := filterCost(best)
+
+			for _, rowFilter := range []PixFilter{
+				PixFilterSub, PixFilterUp,
+				PixFilterAverage, PixFilterPaeth,
+			} {
+				filterRow(rowFilter, candidate, row, prev, bytesPerPixel)
+
+				if cost := filterCost(candidate); cost < bestCost {
+					bestFilter = rowFilter
+					bestCost = cost
+					best, candidate = candidate, best
+				}
+			}
+
+			filtered = append(filtered, byte(bestFilter))
+			filtered = append(filtered, best...)
+			prev = row
+		}
+
+		return filtered, nil
+
+	default:
+		return nil, fmt.Errorf(
+			"unknown pixel filter %d", filter)
+	}
+}
+
+// unfilterPix reverses the filter applied to
+// the pixel data of the picture of the given size.
+func unfilterPix(filtered []byte, width, height, bytesPerPixel int, filter PixFilter) ([]byte, error) {
+	if filter == PixFilterNone {
+		return filtered, nil
+	}
+
+	stride := width * bytesPerPixel
+
+	if bytesPerPixel <= 0 || len(filtered) != filteredPixSize(stride*height, height, filter) {
+		return nil, fmt.Errorf(
+			"filtered data of %d bytes doesn't match the %dx%d picture with %d bytes per pixel",
+			len(filtered), width, height, bytesPerPixel)
+	}
+
+	switch filter {
+	case PixFilterPlanar:
+		return mergePlanes(filtered, bytesPerPixel), nil
+
+	case PixFilterSub, PixFilterUp, PixFilterAverage, PixFilterPaeth:
+		pix := make([]byte, len(filtered))
+		prev := make([]byte, stride)
+
+		for y := 0; y < height; y++ {
+			row := pix[y*stride : (y+1)*stride]
+			copy(row, filtered[y*stride:(y+1)*stride])
+			unfilterRow(filter, row, prev, bytesPerPixel)
+			prev = row
+		}
+
+		return pix, nil
+
+	case PixFilterAdaptive:
+		pix := make([]byte, stride*height)
+		prev := make([]byte, stride)
+
+		for y := 0; y < height; y++ {
+			offset := y * (stride + 1)
+			rowFilter := PixFilter(filtered[offset])
+			row := pix[y*stride : (y+1)*stride]
+			copy(row, filtered[offset+1:offset+1+stride])
+
+			switch rowFilter {
+			case PixFilterNone:
+
+			case PixFilterSub, PixFilterUp, PixFilterAverage, PixFilterPaeth:
+				unfilterRow(rowFilter, row, prev, bytesPerPixel)
+
+			default:
+				return nil, fmt.Errorf(
+					"invalid row filter %d in row %d", rowFilter, y)
+			}
+
+			prev = row
+		}
+
+		return pix, nil
+
+	default:
+		return nil, fmt.Errorf(
+			"unknown pixel filter %d", filter)
+	}
+}
+
+// filterRow applies the prediction filter to the row
+// writing the residuals to dst. prev is the previous
+// unfiltered row or zeros for the first one.
+func filterRow(filter PixFilter, dst, row, prev []byte, bytesPerPixel int) {
+	for i := range row {
+		var left, upLeft byte
+		up := prev[i]
+
+		if i >= bytesPerPixel {
+			left = row[i-bytesPerPixel]
+			upLeft = prev[i-bytesPerPixel]
+		}
+
+		dst[i] = row[i] - predict(filter, left, up, upLeft)
+	}
+}
+
+// unfilterRow reverses the prediction filter
+// of the row in place. prev is the previous
+// unfiltered row or zeros for the first one.
+func unfilterRow(filter PixFilter, row, prev []byte, bytesPerPixel int) {
+	for i := range row {
+		var left, upLeft byte
+		up := prev[i]
+
+		if i >= bytesPerPixel {
+			left = row[i-bytesPerPixel]
+			upLeft = prev[i-bytesPerPixel]
+		}
+
+		row[i] += predict(filter, left, up, upLeft)
+	}
+}
+
+// predict returns the value predicted by the filter
+// out of the neighbouring bytes of the same channel.
+func predict(filter PixFilter, left, up, upLeft byte) byte {
+	switch filter {
+	case PixFilterSub:
+		return left
+
+	case PixFilterUp:
+		return up
+
+	case PixFilterAverage:
+		return byte((int(left) + int(up)) / 2)
+
+	case PixFilterPaeth:
+		return paeth(left, up, upLeft)
+
+	default:
+		return 0
+	}
+}
+
+// paeth is the Paeth predictor
+// as defined in the PNG specification.
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa := abs(p - int(a))
+	pb := abs(p - int(b))
+	pc := abs(p - int(c))
+
+	if pa <= pb && pa <= pc {
+		return a
+	}
+
+	if pb <= pc {
+		return b
+	}
+
+	return c
+}
+
+// filterCost estimates how well the filtered
+// row compresses: the lesser the better.
+func filterCost(row []byte) int {
+	cost := 0
+
+	for _, b := range row {
+		cost += abs(int(int8(b)))
+	}
+
+	return cost
+}
+
+// splitPlanes rearranges the interleaved
+// pixel data into separate channel planes.
+func splitPlanes(pix []byte, bytesPerPixel int) []byte {
+	planes := make([]byte, len(pix))
+	pixelCount := len(pix) / bytesPerPixel
+
+	for i := 0; i < pixelCount; i++ {
+		for channel := 0; channel < bytesPerPixel; channel++ {
+			planes[channel*pixelCount+i] = pix[i*bytesPerPixel+channel]
+		}
+	}
+
+	return planes
+}
+
+// mergePlanes interleaves the separate
+// channel planes into the pixel data.
+func mergePlanes(planes []byte, bytesPerPixel int) []byte {
+	pix := make([]byte, len(planes))
+	pixelCount := len(planes) / bytesPerPixel
+
+	for i := 0; i < pixelCount; i++ {
+		for channel := 0; channel < bytesPerPixel; channel++ {
+			pix[i*bytesPerPixel+channel] = planes[channel*pixelCount+i]
+		}
+	}
+
+	return pix
+}
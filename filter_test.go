@@ -0,0 +1,47 @@
+package codec_test
+
+import (
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressFilteredPicture(t *testing.T) {
+	picture := testPictureRGBA(t)
+
+	filters := []codec.PixFilter{
+		codec.PixFilterNone,
+		codec.PixFilterSub,
+		codec.PixFilterUp,
+		codec.PixFilterAverage,
+		codec.PixFilterPaeth,
+		codec.PixFilterAdaptive,
+		codec.PixFilterPlanar,
+	}
+	sizes := map[codec.PixFilter]int{}
+
+	for _, filter := range filters {
+		compressedPicture, err := picture.CompressFiltered(filter)
+		assert.Nil(t, err, filter.String())
+		assert.Equal(t, filter, compressedPicture.PixFilter)
+		sizes[filter] = len(compressedPicture.CompressedPix)
+
+		data, err := compressedPicture.ToBytes()
+		assert.Nil(t, err, filter.String())
+		deserializedPicture, err := codec.CompressedPictureFromBytes(data)
+		assert.Nil(t, err, filter.String())
+
+		decompressedPicture, err := deserializedPicture.Decompress()
+		assert.Nil(t, err, filter.String())
+		assert.Equal(t, picture.Pix, decompressedPicture.Pix, filter.String())
+	}
+
+	for _, filter := range filters {
+		t.Logf("%-8s %7d bytes (%.1f%% of None)", filter, sizes[filter],
+			100*float64(sizes[filter])/float64(sizes[codec.PixFilterNone]))
+	}
+
+	assert.Less(t, sizes[codec.PixFilterPaeth], sizes[codec.PixFilterNone])
+	assert.Less(t, sizes[codec.PixFilterAdaptive], sizes[codec.PixFilterNone])
+}
@@ -0,0 +1,57 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// formatMagic precedes the version of the serialized
+// resource. Its high bit is set so it cannot be taken
+// for the non-negative size the legacy unversioned
+// layouts start with.
+const formatMagic uint32 = 0x89414C43
+
+const (
+	compressedFramesFormatVersion  int32 = 1 // compressedFramesFormatVersion adds the compression algorithm.
+	compressedPictureFormatVersion int32 = 1 // compressedPictureFormatVersion adds the filter, chunks, color space, palette, origin and mipmaps.
+)
+
+// writeFormatHeader writes the magic
+// followed by the format version.
+func writeFormatHeader(buffer *bytes.Buffer, version int32) error {
+	err := binary.Write(buffer, binary.BigEndian, formatMagic)
+
+	if err != nil {
+		return err
+	}
+
+	return binary.Write(buffer, binary.BigEndian, version)
+}
+
+// readFormatHeader returns the format version of the
+// serialized resource. The legacy data without the
+// header is left intact and reported as the version 0.
+func readFormatHeader(buffer *bytes.Buffer, latestVersion int32) (int32, error) {
+	header := buffer.Bytes()
+
+	if len(header) < 4 || binary.BigEndian.Uint32(header) != formatMagic {
+		return 0, nil
+	}
+
+	buffer.Next(4)
+
+	var version int32
+	err := binary.Read(buffer, binary.BigEndian, &version)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if version < 1 || version > latestVersion {
+		return 0, fmt.Errorf(
+			"unsupported format version %d", version)
+	}
+
+	return version, nil
+}
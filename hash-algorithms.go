@@ -1,22 +1,200 @@
 package codec
 
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
 type HashAlgorithm int
 
 const (
 	HashAlgorithmKeccak256 HashAlgorithm = iota // HashAlgorithmKeccak256 is the identifier of the Keccak256 hashing algorithm.
 	HashAlgorithmSHA256                         // HashAlgorithmSHA256 is the identifier of the SHA-256 hashing algorithm.
+	HashAlgorithmSHA512                         // HashAlgorithmSHA512 is the identifier of the SHA-512 hashing algorithm.
+	HashAlgorithmCRC32C                         // HashAlgorithmCRC32C is the identifier of the CRC-32 checksum with the Castagnoli polynomial.
+	HashAlgorithmCRC64                          // HashAlgorithmCRC64 is the identifier of the CRC-64 checksum with the ECMA polynomial.
+	HashAlgorithmFNV1a                          // HashAlgorithmFNV1a is the identifier of the 64-bit FNV-1a non-cryptographic hashing algorithm.
 )
 
+// NewHashFunc creates a new hash
+// state of the hashing algorithm.
+type NewHashFunc func() hash.Hash
+
+// hashAlgorithmEntry is a record
+// of the hash algorithm registry.
+type hashAlgorithmEntry struct {
+	name    string
+	newHash NewHashFunc
+}
+
+var (
+	// crc32CTable is the precomputed table
+	// of the Castagnoli polynomial.
+	crc32CTable = crc32.MakeTable(crc32.Castagnoli)
+	// crc64Table is the precomputed table
+	// of the ECMA polynomial.
+	crc64Table = crc64.MakeTable(crc64.ECMA)
+)
+
+var (
+	// hashAlgorithmsMutex guards
+	// the hash algorithm registry.
+	hashAlgorithmsMutex sync.RWMutex
+	// hashAlgorithms is the map of all the
+	// registered hashing algorithms.
+	hashAlgorithms = map[HashAlgorithm]*hashAlgorithmEntry{
+		HashAlgorithmKeccak256: {
+			name:    "Keccak256",
+			newHash: func() hash.Hash { return crypto.NewKeccakState() },
+		},
+		HashAlgorithmSHA256: {
+			name:    "SHA-256",
+			newHash: sha256.New,
+		},
+		HashAlgorithmSHA512: {
+			name:    "SHA-512",
+			newHash: sha512.New,
+		},
+		HashAlgorithmCRC32C: {
+			name:    "CRC-32C",
+			newHash: func() hash.Hash { return crc32.New(crc32CTable) },
+		},
+		HashAlgorithmCRC64: {
+			name:    "CRC-64",
+			newHash: func() hash.Hash { return crc64.New(crc64Table) },
+		},
+		HashAlgorithmFNV1a: {
+			name:    "FNV-1a",
+			newHash: func() hash.Hash { return fnv.New64a() },
+		},
+	}
+)
+
+// RegisterHashAlgorithm adds a new hashing
+// algorithm to the registry so it can be used
+// by codecs and resolved from the serialized
+// resources. Both the identifier and the
+// name must be unique.
+func RegisterHashAlgorithm(id HashAlgorithm, name string, newHash NewHashFunc) error {
+	if name == "" {
+		return fmt.Errorf(
+			"hash algorithm %d has no name", id)
+	}
+
+	if newHash == nil {
+		return fmt.Errorf(
+			"hash algorithm '%s' lacks the hash constructor", name)
+	}
+
+	hashAlgorithmsMutex.Lock()
+	defer hashAlgorithmsMutex.Unlock()
+
+	if entry, ok := hashAlgorithms[id]; ok {
+		return fmt.Errorf(
+			"hash algorithm %d is already registered as '%s'",
+			id, entry.name)
+	}
+
+	for registeredID, entry := range hashAlgorithms {
+		if entry.name == name {
+			return fmt.Errorf(
+				"hash algorithm name '%s' is already taken by %d",
+				name, registeredID)
+		}
+	}
+
+	hashAlgorithms[id] = &hashAlgorithmEntry{
+		name:    name,
+		newHash: newHash,
+	}
+
+	return nil
+}
+
+// unregisterHashAlgorithm removes the
+// hash algorithm from the registry.
+func unregisterHashAlgorithm(id HashAlgorithm) {
+	hashAlgorithmsMutex.Lock()
+	defer hashAlgorithmsMutex.Unlock()
+
+	delete(hashAlgorithms, id)
+}
+
+// LookupHashAlgorithm returns the hash
+// constructor of the registered algorithm.
+func LookupHashAlgorithm(id HashAlgorithm) (NewHashFunc, bool) {
+	entry, ok := lookupHashAlgorithm(id)
+
+	if !ok {
+		return nil, false
+	}
+
+	return entry.newHash, true
+}
+
+// HashAlgorithmByName returns the identifier
+// of the registered hash algorithm with
+// the given name.
+func HashAlgorithmByName(name string) (HashAlgorithm, bool) {
+	hashAlgorithmsMutex.RLock()
+	defer hashAlgorithmsMutex.RUnlock()
+
+	for id, entry := range hashAlgorithms {
+		if entry.name == name {
+			return id, true
+		}
+	}
+
+	return 0, false
+}
+
+// HashAlgorithms returns the identifiers of
+// all the registered hash algorithms
+// in ascending order.
+func HashAlgorithms() []HashAlgorithm {
+	hashAlgorithmsMutex.RLock()
+	defer hashAlgorithmsMutex.RUnlock()
+
+	ids := make([]HashAlgorithm, 0, len(hashAlgorithms))
+
+	for id := range hashAlgorithms {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	return ids
+}
+
+// lookupHashAlgorithm returns the registry
+// record of the hash algorithm.
+func lookupHashAlgorithm(id HashAlgorithm) (*hashAlgorithmEntry, bool) {
+	hashAlgorithmsMutex.RLock()
+	defer hashAlgorithmsMutex.RUnlock()
+
+	entry, ok := hashAlgorithms[id]
+
+	return entry, ok
+}
+
 // String returns the name of the hashing algorithm.
 func (alg HashAlgorithm) String() string {
-	switch alg {
-	case HashAlgorithmKeccak256:
-		return "Keccak256"
-
-	case HashAlgorithmSHA256:
-		return "SHA-256"
+	entry, ok := lookupHashAlgorithm(alg)
 
-	default:
+	if !ok {
 		return ""
 	}
+
+	return entry.name
 }
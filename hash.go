@@ -2,43 +2,81 @@ package codec
 
 import (
 	"crypto/sha256"
-	"fmt"
+	"crypto/sha512"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"hash/fnv"
 
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
-var (
-	// hash is the map of all the available
-	// hashing algorithms.
-	hash = map[HashAlgorithm]func([]byte) ([]byte, error){
-		HashAlgorithmKeccak256: HashKeccak256,
-		HashAlgorithmSHA256:    HashSHA256,
-	}
-)
-
 // Hash computes the hash sum of the
 // given data using the consented
 // hashing algorithm.
 func Hash(in []byte) ([]byte, error) {
-	hashAlgorithm, ok := hash[ConsentedHashAlgorithm]
+	return DefaultCodec().Hash(in)
+}
+
+// NewHasher returns a new hash state of the
+// registered hashing algorithm so the data can
+// be hashed incrementally. It returns nil if
+// the algorithm is not registered.
+func NewHasher(alg HashAlgorithm) hash.Hash {
+	newHash, ok := LookupHashAlgorithm(alg)
 
 	if !ok {
-		return nil, fmt.Errorf(
-			"hash algorithm '%s' not found",
-			ConsentedHashAlgorithm)
+		return nil
 	}
 
-	return hashAlgorithm(in)
+	return newHash()
 }
 
 // HashKeccak256 is the Keccak256 hashing algorithm.
 func HashKeccak256(in []byte) ([]byte, error) {
-	hash := crypto.NewKeccakState()
+	return hashWith(crypto.NewKeccakState(), in)
+}
+
+// HashSHA256 is the SHA-256 hashing algorithm.
+func HashSHA256(in []byte) ([]byte, error) {
+	sum := sha256.Sum256(in)
+
+	return sum[:], nil
+}
+
+// HashSHA512 is the SHA-512 hashing algorithm.
+func HashSHA512(in []byte) ([]byte, error) {
+	sum := sha512.Sum512(in)
+
+	return sum[:], nil
+}
+
+// HashCRC32C is the CRC-32 checksum
+// with the Castagnoli polynomial.
+func HashCRC32C(in []byte) ([]byte, error) {
+	return hashWith(crc32.New(crc32CTable), in)
+}
+
+// HashCRC64 is the CRC-64 checksum
+// with the ECMA polynomial.
+func HashCRC64(in []byte) ([]byte, error) {
+	return hashWith(crc64.New(crc64Table), in)
+}
+
+// HashFNV1a is the 64-bit FNV-1a
+// hashing algorithm.
+func HashFNV1a(in []byte) ([]byte, error) {
+	return hashWith(fnv.New64a(), in)
+}
+
+// hashWith writes all the data to
+// the hash state and returns the sum.
+func hashWith(state hash.Hash, in []byte) ([]byte, error) {
 	total := 0
 	var written int
 	var err error
 
-	for written, err = hash.Write(in[total:]); written > 0 && err == nil; written, err = hash.Write(in[total:]) {
+	for written, err = state.Write(in[total:]); written > 0 && err == nil; written, err = state.Write(in[total:]) {
 		total += written
 	}
 
@@ -46,14 +84,7 @@ func HashKeccak256(in []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	sum := hash.Sum(nil)
+	sum := state.Sum(nil)
 
 	return sum, nil
 }
-
-// HashSHA256 is the SHA-256 hashing algorithm.
-func HashSHA256(in []byte) ([]byte, error) {
-	sum := sha256.Sum256(in)
-
-	return sum[:], nil
-}
@@ -0,0 +1,66 @@
+package codec_test
+
+import (
+	"crypto/md5"
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashAlgorithms(t *testing.T) {
+	data := []byte("cirno is the strongest")
+	hashFuncs := map[codec.HashAlgorithm]func([]byte) ([]byte, error){
+		codec.HashAlgorithmKeccak256: codec.HashKeccak256,
+		codec.HashAlgorithmSHA256:    codec.HashSHA256,
+		codec.HashAlgorithmSHA512:    codec.HashSHA512,
+		codec.HashAlgorithmCRC32C:    codec.HashCRC32C,
+		codec.HashAlgorithmCRC64:     codec.HashCRC64,
+		codec.HashAlgorithmFNV1a:     codec.HashFNV1a,
+	}
+
+	for algorithm, hashFunc := range hashFuncs {
+		expected, err := hashFunc(data)
+		assert.Nil(t, err, algorithm.String())
+
+		actual, err := codec.NewCodec(algorithm, codec.PixFormatRGBA,
+			codec.CompressionAlgorithmLZWOrderLSBLitWidth8).Hash(data)
+		assert.Nil(t, err, algorithm.String())
+		assert.Equal(t, expected, actual, algorithm.String())
+	}
+
+	const md5Algorithm codec.HashAlgorithm = 1000
+	err := codec.RegisterHashAlgorithm(md5Algorithm, "MD5", md5.New)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		codec.UnregisterHashAlgorithm(md5Algorithm)
+	})
+	assert.Equal(t, "MD5", md5Algorithm.String())
+	assert.Contains(t, codec.HashAlgorithms(), md5Algorithm)
+
+	err = codec.RegisterHashAlgorithm(md5Algorithm, "MD5-2", md5.New)
+	assert.NotNil(t, err)
+	err = codec.RegisterHashAlgorithm(md5Algorithm+1, "SHA-256", md5.New)
+	assert.NotNil(t, err)
+}
+
+func TestNewHasher(t *testing.T) {
+	data := []byte("cirno is the strongest")
+
+	for _, algorithm := range codec.HashAlgorithms() {
+		hasher := codec.NewHasher(algorithm)
+		assert.NotNil(t, hasher, algorithm.String())
+
+		for i := 0; i < len(data); i += 5 {
+			_, err := hasher.Write(data[i:min(i+5, len(data))])
+			assert.Nil(t, err, algorithm.String())
+		}
+
+		expected, err := codec.NewCodec(algorithm, codec.PixFormatRGBA,
+			codec.CompressionAlgorithmLZWOrderLSBLitWidth8).Hash(data)
+		assert.Nil(t, err, algorithm.String())
+		assert.Equal(t, expected, hasher.Sum(nil), algorithm.String())
+	}
+
+	assert.Nil(t, codec.NewHasher(-1))
+}
@@ -0,0 +1,67 @@
+package codec_test
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"math"
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+// testImageRGBA decodes the test image
+// and draws it into the RGBA image.
+func testImageRGBA(tb testing.TB) *image.RGBA {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(tb, err)
+	imgRGBA := image.NewRGBA(image.Rect(0, 0,
+		img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(imgRGBA, imgRGBA.Bounds(),
+		img, img.Bounds().Min, draw.Src)
+
+	return imgRGBA
+}
+
+// testPictureRGBA returns the picture of the
+// PixFormatRGBA format made of the test image
+// and hashed with the consented algorithm.
+func testPictureRGBA(tb testing.TB) *codec.PictureData {
+	imgRGBA := testImageRGBA(tb)
+	hash, err := codec.Hash(imgRGBA.Pix)
+	assert.Nil(tb, err)
+
+	return &codec.PictureData{
+		Width:         int32(imgRGBA.Rect.Dx()),
+		Height:        int32(imgRGBA.Rect.Dy()),
+		Pix:           imgRGBA.Pix,
+		Hash:          hash,
+		PixFormat:     codec.PixFormatRGBA,
+		HashAlgorithm: codec.ConsentedHashAlgorithm,
+	}
+}
+
+// psnr computes the peak signal-to-noise
+// ratio between the two RGBA pixel arrays.
+func psnr(original, distorted []byte) float64 {
+	sum := 0.0
+
+	for i := range original {
+		diff := float64(original[i]) - float64(distorted[i])
+		sum += diff * diff
+	}
+
+	mse := sum / float64(len(original))
+
+	return 10 * math.Log10(255*255/mse)
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
@@ -0,0 +1,88 @@
+package codec
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+)
+
+// ToImage converts the picture back to the image
+// undoing the vertical flip of the pictures with
+// the bottom-left origin. The pictures of the
+// PixFormatRGBA64 format become *image.NRGBA64,
+// the rest become *image.NRGBA.
+func (picture *PictureData) ToImage() (image.Image, error) {
+	width := int(picture.Width)
+	height := int(picture.Height)
+
+	if len(picture.Pix) != picture.PixFormat.PixSize(width, height) {
+		return nil, fmt.Errorf(
+			"pixel data of %d bytes doesn't match the %dx%d picture of format '%s'",
+			len(picture.Pix), width, height, picture.PixFormat)
+	}
+
+	bounds := image.Rect(0, 0, width, height)
+
+	if picture.PixFormat == PixFormatRGBA64 {
+		img := image.NewNRGBA64(bounds)
+		copy(img.Pix, picture.Pix)
+
+		if picture.Origin == OriginBottomLeft {
+			flipRows(img.Pix, img.Stride)
+		}
+
+		return img, nil
+	}
+
+	rgbaPicture, err := picture.ConvertTo(PixFormatRGBA)
+
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewNRGBA(bounds)
+	copy(img.Pix, rgbaPicture.Pix)
+
+	if picture.Origin == OriginBottomLeft {
+		flipRows(img.Pix, img.Stride)
+	}
+
+	return img, nil
+}
+
+// EncodePNG writes the picture
+// to w in the PNG format.
+func (picture *PictureData) EncodePNG(w io.Writer) error {
+	img, err := picture.ToImage()
+
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+}
+
+// ToImage decompresses the picture
+// and converts it back to the image.
+func (compressedPicture *CompressedPictureData) ToImage() (image.Image, error) {
+	picture, err := compressedPicture.Decompress()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return picture.ToImage()
+}
+
+// EncodePNG decompresses the picture
+// and writes it to w in the PNG format.
+func (compressedPicture *CompressedPictureData) EncodePNG(w io.Writer) error {
+	img, err := compressedPicture.ToImage()
+
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+}
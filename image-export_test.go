@@ -0,0 +1,46 @@
+package codec_test
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPictureToImage(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(t, err)
+	imgNRGBA := image.NewNRGBA(image.Rect(0, 0,
+		img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(imgNRGBA, imgNRGBA.Bounds(),
+		img, img.Bounds().Min, draw.Src)
+
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+	compressedPicture, err := picture.Compress()
+	assert.Nil(t, err)
+
+	buffer := bytes.NewBuffer([]byte{})
+	err = compressedPicture.EncodePNG(buffer)
+	assert.Nil(t, err)
+
+	decodedImg, err := png.Decode(buffer)
+	assert.Nil(t, err)
+	decodedNRGBA := image.NewNRGBA(decodedImg.Bounds())
+	draw.Draw(decodedNRGBA, decodedNRGBA.Bounds(),
+		decodedImg, decodedImg.Bounds().Min, draw.Src)
+	assert.Equal(t, imgNRGBA.Rect, decodedNRGBA.Rect)
+	assert.Equal(t, imgNRGBA.Pix, decodedNRGBA.Pix)
+
+	rgbCodec := codec.NewCodec(codec.HashAlgorithmSHA256,
+		codec.PixFormatRGB, codec.CompressionAlgorithmDeflate)
+	rgbPicture, err := rgbCodec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+	rgbImg, err := rgbPicture.ToImage()
+	assert.Nil(t, err)
+	assert.Equal(t, imgNRGBA.Pix, rgbImg.(*image.NRGBA).Pix)
+}
@@ -0,0 +1,227 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	lz4MinMatch      = 4       // lz4MinMatch is the shortest match the block format can encode.
+	lz4LastLiterals  = 5       // lz4LastLiterals is the number of trailing bytes that must be literals.
+	lz4MatchFindLim  = 12      // lz4MatchFindLim is the distance from the end of the block after which no match may start.
+	lz4MaxOffset     = 1 << 16 // lz4MaxOffset is the exclusive upper bound of a match offset.
+	lz4HashLog       = 16      // lz4HashLog is the binary logarithm of the match finder table size.
+	lz4HashMulFactor = 2654435761
+)
+
+// lz4CompressBound returns the maximum size
+// of the LZ4 block produced out of n bytes.
+func lz4CompressBound(n int) int {
+	return n + n/255 + 16
+}
+
+// lz4Hash returns the match finder
+// table index of the 4-byte sequence.
+func lz4Hash(sequence uint32) uint32 {
+	return (sequence * lz4HashMulFactor) >> (32 - lz4HashLog)
+}
+
+// lz4CompressBlock compresses the data
+// into a single LZ4 block using the greedy
+// hash table match finder.
+func lz4CompressBlock(src []byte) []byte {
+	dst := make([]byte, 0, lz4CompressBound(len(src)))
+	anchor := 0
+
+	if len(src) > lz4MatchFindLim {
+		// Positions are stored shifted by one
+		// so that zero marks an empty slot.
+		table := make([]int32, 1<<lz4HashLog)
+		matchLimit := len(src) - lz4LastLiterals
+
+		for i := 0; i+lz4MatchFindLim <= len(src); {
+			sequence := binary.LittleEndian.Uint32(src[i:])
+			h := lz4Hash(sequence)
+			ref := int(table[h]) - 1
+			table[h] = int32(i + 1)
+
+			if ref < 0 || i-ref >= lz4MaxOffset ||
+				binary.LittleEndian.Uint32(src[ref:]) != sequence {
+				i++
+				continue
+			}
+
+			// Extend the match backwards
+			// over the pending literals.
+			for i > anchor && ref > 0 && src[i-1] == src[ref-1] {
+				i--
+				ref--
+			}
+
+			matchLength := lz4MinMatch
+
+			for i+matchLength < matchLimit && src[i+matchLength] == src[ref+matchLength] {
+				matchLength++
+			}
+
+			dst = lz4AppendSequence(dst, src[anchor:i], i-ref, matchLength)
+			i += matchLength
+			anchor = i
+		}
+	}
+
+	// The block always ends with
+	// a literals-only sequence.
+	literalLength := len(src) - anchor
+	dst = append(dst, byte(min(literalLength, 15)<<4))
+
+	if literalLength >= 15 {
+		dst = lz4AppendLength(dst, literalLength-15)
+	}
+
+	return append(dst, src[anchor:]...)
+}
+
+// lz4AppendSequence appends the literals
+// followed by the match to the LZ4 block.
+func lz4AppendSequence(dst, literals []byte, offset, matchLength int) []byte {
+	literalLength := len(literals)
+	matchLength -= lz4MinMatch
+	token := byte(min(literalLength, 15)<<4) | byte(min(matchLength, 15))
+	dst = append(dst, token)
+
+	if literalLength >= 15 {
+		dst = lz4AppendLength(dst, literalLength-15)
+	}
+
+	dst = append(dst, literals...)
+	dst = append(dst, byte(offset), byte(offset>>8))
+
+	if matchLength >= 15 {
+		dst = lz4AppendLength(dst, matchLength-15)
+	}
+
+	return dst
+}
+
+// lz4AppendLength appends the length
+// continuation bytes to the LZ4 block.
+func lz4AppendLength(dst []byte, length int) []byte {
+	for length >= 255 {
+		dst = append(dst, 255)
+		length -= 255
+	}
+
+	return append(dst, byte(length))
+}
+
+// lz4ReadLength reads the length continuation
+// bytes of the LZ4 block starting at the
+// given position.
+func lz4ReadLength(src []byte, pos int) (int, int, error) {
+	length := 0
+
+	for {
+		if pos >= len(src) {
+			return 0, pos, fmt.Errorf(
+				"%w: lz4 length continuation out of bounds", ErrTruncated)
+		}
+
+		b := src[pos]
+		pos++
+		length += int(b)
+
+		if b != 255 {
+			return length, pos, nil
+		}
+	}
+}
+
+// lz4DecompressBlock decompresses the LZ4
+// block appending the output to dst. The
+// decompression stops as soon as dst would
+// grow longer than limit bytes.
+func lz4DecompressBlock(src, dst []byte, limit int) ([]byte, error) {
+	pos := 0
+
+	for pos < len(src) {
+		token := src[pos]
+		pos++
+
+		literalLength := int(token >> 4)
+
+		if literalLength == 15 {
+			extra, next, err := lz4ReadLength(src, pos)
+
+			if err != nil {
+				return nil, err
+			}
+
+			literalLength += extra
+			pos = next
+		}
+
+		if literalLength > len(src)-pos {
+			return nil, fmt.Errorf(
+				"%w: lz4 literals out of bounds at %d", ErrTruncated, pos)
+		}
+
+		if literalLength > limit-len(dst) {
+			return nil, fmt.Errorf("%w: more than %d bytes",
+				ErrTrailingData, limit)
+		}
+
+		dst = append(dst, src[pos:pos+literalLength]...)
+		pos += literalLength
+
+		// The last sequence has no match.
+		if pos == len(src) {
+			break
+		}
+
+		if pos+2 > len(src) {
+			return nil, fmt.Errorf(
+				"%w: lz4 match offset out of bounds at %d", ErrTruncated, pos)
+		}
+
+		offset := int(src[pos]) | int(src[pos+1])<<8
+		pos += 2
+
+		if offset == 0 || offset > len(dst) {
+			return nil, fmt.Errorf(
+				"lz4: invalid match offset %d at %d", offset, pos-2)
+		}
+
+		matchLength := int(token & 15)
+
+		if matchLength == 15 {
+			extra, next, err := lz4ReadLength(src, pos)
+
+			if err != nil {
+				return nil, err
+			}
+
+			matchLength += extra
+			pos = next
+		}
+
+		matchLength += lz4MinMatch
+
+		if matchLength > limit-len(dst) {
+			return nil, fmt.Errorf("%w: more than %d bytes",
+				ErrTrailingData, limit)
+		}
+
+		// Copy the match in chunks that double in size
+		// so overlapping matches replicate correctly.
+		start := len(dst) - offset
+
+		for matchLength > 0 {
+			chunk := min(matchLength, len(dst)-start)
+			dst = append(dst, dst[start:start+chunk]...)
+			matchLength -= chunk
+		}
+	}
+
+	return dst, nil
+}
@@ -0,0 +1,213 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MipmapFilter is the filter the mipmap
+// levels are downsampled with.
+type MipmapFilter int
+
+const (
+	MipmapFilterBox          MipmapFilter = iota // MipmapFilterBox averages the stored channel values.
+	MipmapFilterGammaCorrect                     // MipmapFilterGammaCorrect averages the sRGB colors in linear light.
+)
+
+func (filter MipmapFilter) String() string {
+	switch filter {
+	case MipmapFilterBox:
+		return "box"
+
+	case MipmapFilterGammaCorrect:
+		return "gamma-correct"
+
+	default:
+		return ""
+	}
+}
+
+// GenerateMipmaps produces the chain of the mipmap
+// levels of the picture down to 1×1. The level i+1
+// is at the index i; every level halves the size
+// of the previous one and has the pixel format,
+// the color space and the origin of the picture.
+// The colors are averaged premultiplied by alpha.
+func (picture *PictureData) GenerateMipmaps(filter MipmapFilter) ([]*PictureData, error) {
+	if filter != MipmapFilterBox && filter != MipmapFilterGammaCorrect {
+		return nil, fmt.Errorf("unknown mipmap filter %d", filter)
+	}
+
+	if picture.Width <= 0 || picture.Height <= 0 {
+		return nil, fmt.Errorf(
+			"invalid picture size %dx%d", picture.Width, picture.Height)
+	}
+
+	level, err := picture.toRGBA64()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var toLinear, toSRGB []uint16
+
+	if filter == MipmapFilterGammaCorrect && picture.ColorSpace == ColorSpaceSRGB {
+		toLinear = srgbToLinearTable()
+		toSRGB = linearToSRGBTable()
+	}
+
+	mipmaps := []*PictureData{}
+
+	for level.Width > 1 || level.Height > 1 {
+		pix := downsample(level.Pix, int(level.Width), int(level.Height),
+			toLinear, toSRGB)
+		next := *level
+		next.Width = max(level.Width/2, 1)
+		next.Height = max(level.Height/2, 1)
+		level, err = next.withPix(pix, PixFormatRGBA64)
+
+		if err != nil {
+			return nil, err
+		}
+
+		mipmap, err := level.ConvertTo(picture.PixFormat)
+
+		if err != nil {
+			return nil, err
+		}
+
+		mipmaps = append(mipmaps, mipmap)
+	}
+
+	return mipmaps, nil
+}
+
+// downsample halves the width×height picture of the
+// PixFormatRGBA64 format averaging the boxes of the
+// source pixels premultiplied by alpha. If the tables
+// are set, the colors are averaged in linear light.
+func downsample(pix []byte, width, height int, toLinear, toSRGB []uint16) []byte {
+	dstWidth := max(width/2, 1)
+	dstHeight := max(height/2, 1)
+	dst := make([]byte, dstWidth*dstHeight*8)
+
+	for y := 0; y < dstHeight; y++ {
+		// The box bounds spread the odd source
+		// row and column over the neighbours.
+		fromY, toY := y*height/dstHeight, (y+1)*height/dstHeight
+
+		for x := 0; x < dstWidth; x++ {
+			fromX, toX := x*width/dstWidth, (x+1)*width/dstWidth
+			var sums [3]uint64
+			var alphaSum uint64
+
+			for sy := fromY; sy < toY; sy++ {
+				for sx := fromX; sx < toX; sx++ {
+					offset := (sy*width + sx) * 8
+					a := uint64(binary.BigEndian.Uint16(pix[offset+6:]))
+
+					for ch := 0; ch < 3; ch++ {
+						v := binary.BigEndian.Uint16(pix[offset+2*ch:])
+
+						if toLinear != nil {
+							v = toLinear[v]
+						}
+
+						sums[ch] += uint64(v) * a
+					}
+
+					alphaSum += a
+				}
+			}
+
+			count := uint64((toY - fromY) * (toX - fromX))
+			offset := (y*dstWidth + x) * 8
+
+			for ch := 0; ch < 3; ch++ {
+				var v uint16
+
+				if alphaSum > 0 {
+					v = uint16((sums[ch] + alphaSum/2) / alphaSum)
+				}
+
+				if toSRGB != nil {
+					v = toSRGB[v]
+				}
+
+				binary.BigEndian.PutUint16(dst[offset+2*ch:], v)
+			}
+
+			binary.BigEndian.PutUint16(dst[offset+6:],
+				uint16((alphaSum+count/2)/count))
+		}
+	}
+
+	return dst
+}
+
+// CompressWithMipmaps generates the mipmap chain
+// of the picture and compresses the picture along
+// with its levels using the consented algorithms.
+func (picture *PictureData) CompressWithMipmaps(filter MipmapFilter) (*CompressedPictureData, error) {
+	return DefaultCodec().CompressPictureWithMipmaps(picture, filter)
+}
+
+// CompressPictureWithMipmaps generates the mipmap
+// chain of the picture and compresses the picture
+// along with its levels with the settings of the codec.
+// Every level is compressed independently so it can
+// be decompressed on its own.
+func (c *Codec) CompressPictureWithMipmaps(picture *PictureData, filter MipmapFilter) (*CompressedPictureData, error) {
+	mipmaps, err := picture.GenerateMipmaps(filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compressedPicture, err := c.CompressPicture(picture)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mipmap := range mipmaps {
+		compressedMipmap, err := c.CompressPicture(mipmap)
+
+		if err != nil {
+			return nil, err
+		}
+
+		compressedPicture.Mipmaps = append(compressedPicture.Mipmaps, compressedMipmap)
+	}
+
+	return compressedPicture, nil
+}
+
+// MipmapLevels returns the number of the stored
+// levels including the picture itself.
+func (compressedPicture *CompressedPictureData) MipmapLevels() int {
+	return len(compressedPicture.Mipmaps) + 1
+}
+
+// DecompressMipmap decompresses only the given mipmap
+// level of the picture. The level 0 is the picture
+// itself.
+func (compressedPicture *CompressedPictureData) DecompressMipmap(level int) (*PictureData, error) {
+	if level < 0 || level >= compressedPicture.MipmapLevels() {
+		return nil, fmt.Errorf(
+			"mipmap level %d is out of [0; %d)",
+			level, compressedPicture.MipmapLevels())
+	}
+
+	if level == 0 {
+		return compressedPicture.Decompress()
+	}
+
+	picture, err := compressedPicture.Mipmaps[level-1].Decompress()
+
+	if err != nil {
+		return nil, fmt.Errorf("mipmap level %d: %w", level, err)
+	}
+
+	return picture, nil
+}
@@ -0,0 +1,80 @@
+package codec_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateMipmaps(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(t, err)
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+
+	c := codec.NewCodec(codec.HashAlgorithmSHA256,
+		codec.PixFormatRGBA, codec.CompressionAlgorithmDeflate)
+	compressedPicture, err := c.CompressPictureWithMipmaps(picture,
+		codec.MipmapFilterGammaCorrect)
+	assert.Nil(t, err)
+
+	data, err := compressedPicture.ToBytes()
+	assert.Nil(t, err)
+	deserializedPicture, err := codec.CompressedPictureFromBytes(data)
+	assert.Nil(t, err)
+	assert.Equal(t, compressedPicture.MipmapLevels(), deserializedPicture.MipmapLevels())
+
+	width, height := picture.Width, picture.Height
+
+	for level := 1; level < deserializedPicture.MipmapLevels(); level++ {
+		width, height = max(width/2, 1), max(height/2, 1)
+		mipmap, err := deserializedPicture.DecompressMipmap(level)
+		assert.Nil(t, err)
+		assert.Equal(t, width, mipmap.Width)
+		assert.Equal(t, height, mipmap.Height)
+		assert.Equal(t, codec.PixFormatRGBA, mipmap.PixFormat)
+	}
+
+	assert.Equal(t, int32(1), width)
+	assert.Equal(t, int32(1), height)
+
+	_, err = deserializedPicture.DecompressMipmap(deserializedPicture.MipmapLevels())
+	assert.NotNil(t, err)
+}
+
+func TestMipmapFilters(t *testing.T) {
+	// The black and white checkerboard averages to the
+	// middle gray in linear light which is brighter in
+	// sRGB than the plain average of the stored values.
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.SetNRGBA(0, 0, color.NRGBA{A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	img.SetNRGBA(0, 1, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	img.SetNRGBA(1, 1, color.NRGBA{A: 255})
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+
+	boxMipmaps, err := picture.GenerateMipmaps(codec.MipmapFilterBox)
+	assert.Nil(t, err)
+	assert.Len(t, boxMipmaps, 1)
+	assert.Equal(t, []byte{128, 128, 128, 255}, boxMipmaps[0].Pix)
+
+	gammaMipmaps, err := picture.GenerateMipmaps(codec.MipmapFilterGammaCorrect)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{188, 188, 188, 255}, gammaMipmaps[0].Pix)
+
+	// The transparent pixels don't
+	// darken the neighbouring ones.
+	img.SetNRGBA(0, 0, color.NRGBA{})
+	img.SetNRGBA(1, 1, color.NRGBA{})
+	picture, err = codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+
+	boxMipmaps, err = picture.GenerateMipmaps(codec.MipmapFilterBox)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{255, 255, 255, 128}, boxMipmaps[0].Pix)
+}
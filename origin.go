@@ -0,0 +1,73 @@
+package codec
+
+import (
+	"fmt"
+
+	"github.com/alacrity-engine/core/math/geometry"
+)
+
+// Origin is the corner of the picture
+// its first pixel row and the frame
+// coordinates start from.
+type Origin int
+
+const (
+	OriginBottomLeft Origin = iota // OriginBottomLeft stores the rows bottom-up with Y pointing up as OpenGL expects.
+	OriginTopLeft                  // OriginTopLeft stores the rows top-down with Y pointing down as images do.
+)
+
+func (origin Origin) String() string {
+	switch origin {
+	case OriginBottomLeft:
+		return "bottom-left"
+
+	case OriginTopLeft:
+		return "top-left"
+
+	default:
+		return ""
+	}
+}
+
+// spritesheetFrames returns the rectangles of the
+// frames of the spritesheet applied to the picture
+// of the given size. The spritesheet origin is the
+// corner of its area closest to the picture origin.
+// The frames go row by row from the top one.
+func spritesheetFrames(width, height int32, origin Origin, ss *SpritesheetData) ([]geometry.Rect, error) {
+	if ss.Width <= 0 || ss.Height <= 0 {
+		return nil, fmt.Errorf(
+			"invalid spritesheet size %dx%d", ss.Width, ss.Height)
+	}
+
+	if ss.Orig.X < 0 || ss.Orig.X+ss.Area.PixelWidth > width ||
+		ss.Orig.Y < 0 || ss.Orig.Y+ss.Area.PixelHeight > height {
+		return nil, fmt.Errorf(
+			"the spritesheet cannot be applied to the picture")
+	}
+
+	frames := make([]geometry.Rect, 0, ss.Width*ss.Height)
+	dw := float64(ss.Area.PixelWidth) / float64(ss.Width)
+	dh := float64(ss.Area.PixelHeight) / float64(ss.Height)
+
+	for row := int32(0); row < ss.Height; row++ {
+		for column := int32(0); column < ss.Width; column++ {
+			x := float64(ss.Orig.X) + float64(column)*dw
+			var frame geometry.Rect
+
+			switch origin {
+			case OriginTopLeft:
+				y := float64(ss.Orig.Y) + float64(row)*dh
+				frame = geometry.R(x, y, x+dw, y+dh)
+
+			default:
+				y := float64(ss.Orig.Y+ss.Area.PixelHeight) - float64(row)*dh
+				frame = geometry.R(x, y-dh, x+dw, y)
+			}
+
+			frames = append(frames, frame)
+		}
+	}
+
+	return frames, nil
+}
@@ -0,0 +1,75 @@
+package codec_test
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"testing"
+
+	"github.com/alacrity-engine/core/math/geometry"
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopLeftOrigin(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(t, err)
+	imgNRGBA := image.NewNRGBA(image.Rect(0, 0,
+		img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(imgNRGBA, imgNRGBA.Bounds(),
+		img, img.Bounds().Min, draw.Src)
+
+	c := codec.DefaultCodec()
+	c.Origin = codec.OriginTopLeft
+	picture, err := c.NewPictureFromImage(img)
+	assert.Nil(t, err)
+	assert.Equal(t, codec.OriginTopLeft, picture.Origin)
+	assert.Equal(t, imgNRGBA.Pix, picture.Pix)
+
+	compressedPicture, err := c.CompressPicture(picture)
+	assert.Nil(t, err)
+	data, err := compressedPicture.ToBytes()
+	assert.Nil(t, err)
+	deserializedPicture, err := codec.CompressedPictureFromBytes(data)
+	assert.Nil(t, err)
+	assert.Equal(t, codec.OriginTopLeft, deserializedPicture.Origin)
+
+	restoredImg, err := deserializedPicture.ToImage()
+	assert.Nil(t, err)
+	assert.Equal(t, imgNRGBA.Pix, restoredImg.(*image.NRGBA).Pix)
+}
+
+func TestGetSpritesheetFramesTopLeft(t *testing.T) {
+	pic := &codec.PictureData{
+		Width:  512,
+		Height: 256,
+		Origin: codec.OriginTopLeft,
+	}
+	ss := &codec.SpritesheetData{
+		Width:  3,
+		Height: 2,
+		Orig: codec.OrigData{
+			X: 64,
+			Y: 0,
+		},
+		Area: codec.AreaData{
+			PixelWidth:  3 * 64,
+			PixelHeight: 2 * 64,
+		},
+	}
+
+	frames, err := pic.GetSpritesheetFrames(ss)
+	assert.Nil(t, err)
+	assert.Equal(t, []geometry.Rect{
+		geometry.R(64, 0, 128, 64),
+		geometry.R(128, 0, 192, 64),
+		geometry.R(192, 0, 256, 64),
+		geometry.R(64, 64, 128, 128),
+		geometry.R(128, 64, 192, 128),
+		geometry.R(192, 64, 256, 128),
+	}, frames)
+
+	ss.Orig.Y = 200
+	_, err = pic.GetSpritesheetFrames(ss)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,375 @@
+package codec
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+)
+
+// MaxPaletteSize is the maximum number of colors
+// the palette of the paletted picture can hold.
+const MaxPaletteSize = 256
+
+// paletteBytes packs the palette
+// into the RGBA quadruples.
+func paletteBytes(palette []color.NRGBA) []byte {
+	data := make([]byte, 0, len(palette)*4)
+
+	for _, c := range palette {
+		data = append(data, c.R, c.G, c.B, c.A)
+	}
+
+	return data
+}
+
+// paletteFromBytes unpacks the palette
+// out of the RGBA quadruples.
+func paletteFromBytes(data []byte) []color.NRGBA {
+	palette := make([]color.NRGBA, 0, len(data)/4)
+
+	for i := 0; i+4 <= len(data); i += 4 {
+		palette = append(palette, color.NRGBA{
+			R: data[i],
+			G: data[i+1],
+			B: data[i+2],
+			A: data[i+3],
+		})
+	}
+
+	return palette
+}
+
+// hashPicture computes the hash sum of the pixels
+// preceded by the palette of the picture if any
+// using the hash algorithm of the codec.
+func (c *Codec) hashPicture(palette []color.NRGBA, pix []byte) ([]byte, error) {
+	hasher, err := c.NewHasher()
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = hasher.Write(paletteBytes(palette))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return hashWith(hasher, pix)
+}
+
+// expandPalette replaces the indices
+// with the RGBA colors of the palette.
+func expandPalette(pix []byte, palette []color.NRGBA) ([]byte, error) {
+	expanded := make([]byte, 0, len(pix)*4)
+
+	for i, index := range pix {
+		if int(index) >= len(palette) {
+			return nil, fmt.Errorf(
+				"pixel %d refers to color %d out of the palette of %d colors",
+				i, index, len(palette))
+		}
+
+		c := palette[index]
+		expanded = append(expanded, c.R, c.G, c.B, c.A)
+	}
+
+	return expanded, nil
+}
+
+// colorBox is the box of the color space
+// the median cut algorithm splits.
+type colorBox struct {
+	colors []weightedColor
+}
+
+// weightedColor is the unique color of the
+// picture along with the number of its pixels.
+type weightedColor struct {
+	channels [4]uint8
+	count    int
+}
+
+// widestChannel returns the channel the box
+// spans the most along with its range.
+func (box colorBox) widestChannel() (int, int) {
+	channel, width := 0, -1
+
+	for ch := 0; ch < 4; ch++ {
+		low, high := uint8(255), uint8(0)
+
+		for _, c := range box.colors {
+			low = min(low, c.channels[ch])
+			high = max(high, c.channels[ch])
+		}
+
+		if int(high)-int(low) > width {
+			channel, width = ch, int(high)-int(low)
+		}
+	}
+
+	return channel, width
+}
+
+// average returns the mean color of
+// the box weighted by the pixel counts.
+func (box colorBox) average() color.NRGBA {
+	var sums [4]int
+	total := 0
+
+	for _, c := range box.colors {
+		for ch := 0; ch < 4; ch++ {
+			sums[ch] += int(c.channels[ch]) * c.count
+		}
+
+		total += c.count
+	}
+
+	var mean [4]uint8
+
+	for ch := 0; ch < 4; ch++ {
+		mean[ch] = uint8((sums[ch] + total/2) / total)
+	}
+
+	return color.NRGBA{R: mean[0], G: mean[1], B: mean[2], A: mean[3]}
+}
+
+// split divides the box at the weighted median
+// of its widest channel.
+func (box colorBox) split() (colorBox, colorBox) {
+	channel, _ := box.widestChannel()
+	sort.Slice(box.colors, func(i, j int) bool {
+		left, right := box.colors[i].channels, box.colors[j].channels
+
+		if left[channel] != right[channel] {
+			return left[channel] < right[channel]
+		}
+
+		return colorKey(left) < colorKey(right)
+	})
+
+	total := 0
+
+	for _, c := range box.colors {
+		total += c.count
+	}
+
+	median, accumulated := 1, 0
+
+	for i, c := range box.colors[:len(box.colors)-1] {
+		accumulated += c.count
+		median = i + 1
+
+		if accumulated*2 >= total {
+			break
+		}
+	}
+
+	return colorBox{colors: box.colors[:median]},
+		colorBox{colors: box.colors[median:]}
+}
+
+// colorKey packs the channels into
+// the single comparable value.
+func colorKey(channels [4]uint8) uint32 {
+	return uint32(channels[0])<<24 | uint32(channels[1])<<16 |
+		uint32(channels[2])<<8 | uint32(channels[3])
+}
+
+// medianCut builds the palette of at most
+// the given number of colors out of the
+// RGBA pixels with the median cut algorithm.
+func medianCut(pix []byte, colors int) []color.NRGBA {
+	counts := map[uint32]int{}
+
+	for i := 0; i < len(pix); i += 4 {
+		counts[colorKey([4]uint8(pix[i:i+4]))]++
+	}
+
+	uniqueColors := make([]weightedColor, 0, len(counts))
+
+	for key, count := range counts {
+		uniqueColors = append(uniqueColors, weightedColor{
+			channels: [4]uint8{
+				uint8(key >> 24), uint8(key >> 16),
+				uint8(key >> 8), uint8(key),
+			},
+			count: count,
+		})
+	}
+
+	// Sort the colors for the output
+	// not to depend on the map order.
+	sort.Slice(uniqueColors, func(i, j int) bool {
+		return colorKey(uniqueColors[i].channels) <
+			colorKey(uniqueColors[j].channels)
+	})
+
+	boxes := []colorBox{{colors: uniqueColors}}
+
+	for len(boxes) < colors {
+		// Split the box spanning the widest
+		// range among the splittable ones.
+		widest, widestRange := -1, 0
+
+		for i, box := range boxes {
+			if len(box.colors) < 2 {
+				continue
+			}
+
+			if _, width := box.widestChannel(); width > widestRange {
+				widest, widestRange = i, width
+			}
+		}
+
+		if widest < 0 {
+			break
+		}
+
+		left, right := boxes[widest].split()
+		boxes[widest] = left
+		boxes = append(boxes, right)
+	}
+
+	palette := make([]color.NRGBA, 0, len(boxes))
+
+	for _, box := range boxes {
+		if len(box.colors) > 0 {
+			palette = append(palette, box.average())
+		}
+	}
+
+	return palette
+}
+
+// nearestColor returns the index of the
+// palette color closest to the given one.
+func nearestColor(palette []color.NRGBA, r, g, b, a int) int {
+	nearest, nearestDistance := 0, -1
+
+	for i, c := range palette {
+		dr := r - int(c.R)
+		dg := g - int(c.G)
+		db := b - int(c.B)
+		da := a - int(c.A)
+		distance := dr*dr + dg*dg + db*db + da*da
+
+		if nearestDistance < 0 || distance < nearestDistance {
+			nearest, nearestDistance = i, distance
+		}
+	}
+
+	return nearest
+}
+
+// Quantize returns a new picture of the
+// PixFormatPaletted format with the palette
+// of at most the given number of colors built
+// with the median cut algorithm. If dither is
+// true, the quantization error is diffused
+// with the Floyd–Steinberg algorithm.
+func (picture *PictureData) Quantize(colors int, dither bool) (*PictureData, error) {
+	if colors < 1 || colors > MaxPaletteSize {
+		return nil, fmt.Errorf(
+			"palette size %d is out of [1; %d]",
+			colors, MaxPaletteSize)
+	}
+
+	rgbaPicture, err := picture.ConvertTo(PixFormatRGBA)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pix := rgbaPicture.Pix
+	width := int(picture.Width)
+	height := int(picture.Height)
+
+	if len(pix) != PixFormatRGBA.PixSize(width, height) {
+		return nil, fmt.Errorf(
+			"pixel data of %d bytes doesn't match the %dx%d picture",
+			len(pix), width, height)
+	}
+
+	palette := medianCut(pix, colors)
+	indices := make([]byte, width*height)
+
+	if dither {
+		ditherFloydSteinberg(pix, indices, width, height, palette)
+	} else {
+		cache := map[uint32]byte{}
+
+		for i := range indices {
+			key := colorKey([4]uint8(pix[i*4 : i*4+4]))
+			index, ok := cache[key]
+
+			if !ok {
+				index = byte(nearestColor(palette,
+					int(pix[i*4]), int(pix[i*4+1]),
+					int(pix[i*4+2]), int(pix[i*4+3])))
+				cache[key] = index
+			}
+
+			indices[i] = index
+		}
+	}
+
+	hashCodec := DefaultCodec()
+	hashCodec.HashAlgorithm = picture.HashAlgorithm
+	hash, err := hashCodec.hashPicture(palette, indices)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PictureData{
+		Width:         picture.Width,
+		Height:        picture.Height,
+		Pix:           indices,
+		Hash:          hash,
+		PixFormat:     PixFormatPaletted,
+		HashAlgorithm: picture.HashAlgorithm,
+		ColorSpace:    picture.ColorSpace,
+		Palette:       palette,
+		Origin:        picture.Origin,
+	}, nil
+}
+
+// ditherFloydSteinberg maps the RGBA pixels to the
+// palette indices diffusing the quantization error
+// to the neighbouring pixels.
+func ditherFloydSteinberg(pix, indices []byte, width, height int, palette []color.NRGBA) {
+	// The errors of the current
+	// and the next rows in 1/16.
+	current := make([][4]int, width+2)
+	next := make([][4]int, width+2)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			offset := (y*width + x) * 4
+			var channels [4]int
+
+			for ch := 0; ch < 4; ch++ {
+				channels[ch] = max(min(int(pix[offset+ch])+
+					(current[x+1][ch]+8)>>4, 255), 0)
+			}
+
+			index := nearestColor(palette, channels[0],
+				channels[1], channels[2], channels[3])
+			indices[y*width+x] = byte(index)
+			c := palette[index]
+			quantized := [4]int{int(c.R), int(c.G), int(c.B), int(c.A)}
+
+			for ch := 0; ch < 4; ch++ {
+				diff := channels[ch] - quantized[ch]
+				current[x+2][ch] += diff * 7
+				next[x][ch] += diff * 3
+				next[x+1][ch] += diff * 5
+				next[x+2][ch] += diff
+			}
+		}
+
+		current, next = next, current
+		clear(next)
+	}
+}
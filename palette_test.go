@@ -0,0 +1,53 @@
+package codec_test
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantizePicture(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(t, err)
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+
+	for _, dither := range []bool{false, true} {
+		palettedPicture, err := picture.Quantize(codec.MaxPaletteSize, dither)
+		assert.Nil(t, err)
+		assert.Equal(t, codec.PixFormatPaletted, palettedPicture.PixFormat)
+		assert.LessOrEqual(t, len(palettedPicture.Palette), codec.MaxPaletteSize)
+		assert.Equal(t, len(picture.Pix)/4, len(palettedPicture.Pix))
+
+		compressedPicture, err := palettedPicture.Compress()
+		assert.Nil(t, err)
+		data, err := compressedPicture.ToBytes()
+		assert.Nil(t, err)
+		deserializedPicture, err := codec.CompressedPictureFromBytes(data)
+		assert.Nil(t, err)
+		assert.Equal(t, palettedPicture.Palette, deserializedPicture.Palette)
+
+		rgbaPicture, err := deserializedPicture.DecompressAs(codec.PixFormatRGBA)
+		assert.Nil(t, err)
+		assert.Equal(t, codec.PixFormatRGBA, rgbaPicture.PixFormat)
+		assert.Greater(t, psnr(picture.Pix, rgbaPicture.Pix), 28.0)
+	}
+}
+
+func TestPaletteHashMismatch(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(t, err)
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+	palettedPicture, err := picture.Quantize(16, false)
+	assert.Nil(t, err)
+	compressedPicture, err := palettedPicture.Compress()
+	assert.Nil(t, err)
+
+	compressedPicture.Palette[0].R++
+	_, err = compressedPicture.Decompress()
+	assert.ErrorIs(t, err, codec.ErrHashMismatch)
+}
@@ -0,0 +1,166 @@
+package codec
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// CompressParallel splits the pixels of the picture
+// into chunks of chunkSize bytes and compresses them
+// using the given number of goroutines. The output
+// doesn't depend on the number of workers.
+func (picture *PictureData) CompressParallel(chunkSize, workers int) (*CompressedPictureData, error) {
+	c := DefaultCodec()
+	c.ChunkSize = chunkSize
+	c.Workers = workers
+
+	return c.CompressPicture(picture)
+}
+
+// workerCount returns the number of goroutines
+// to process the given number of jobs with.
+func workerCount(workers, jobs int) int {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	return max(min(workers, jobs), 1)
+}
+
+// runParallel calls the job for every index in
+// [0; jobs) using the given number of goroutines
+// and returns the error of the first failed job.
+func runParallel(jobs, workers int, job func(i int) error) error {
+	indices := make(chan int)
+	errs := make([]error, jobs)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workerCount(workers, jobs); w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indices {
+				errs[i] = job(i)
+			}
+		}()
+	}
+
+	for i := 0; i < jobs; i++ {
+		indices <- i
+	}
+
+	close(indices)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compressChunks splits the data into chunks of
+// chunkSize bytes and compresses them concurrently.
+// It returns the concatenation of the compressed
+// chunks along with their sizes.
+func (c *Codec) compressChunks(in []byte, chunkSize int) ([]byte, []int32, error) {
+	if chunkSize <= 0 {
+		return nil, nil, fmt.Errorf(
+			"invalid chunk size %d", chunkSize)
+	}
+
+	chunkCount := max((len(in)+chunkSize-1)/chunkSize, 1)
+	compressedChunks := make([][]byte, chunkCount)
+
+	err := runParallel(chunkCount, c.Workers, func(i int) error {
+		start := i * chunkSize
+		end := min(start+chunkSize, len(in))
+		compressedChunk, err := c.Compress(in[start:end])
+
+		if err != nil {
+			return err
+		}
+
+		compressedChunks[i] = compressedChunk
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	totalSize := 0
+	chunkSizes := make([]int32, chunkCount)
+
+	for i, compressedChunk := range compressedChunks {
+		totalSize += len(compressedChunk)
+		chunkSizes[i] = int32(len(compressedChunk))
+	}
+
+	compressedData := make([]byte, 0, totalSize)
+
+	for _, compressedChunk := range compressedChunks {
+		compressedData = append(compressedData, compressedChunk...)
+	}
+
+	return compressedData, chunkSizes, nil
+}
+
+// decompressChunks decompresses the concatenated
+// chunks concurrently into sourceSize bytes.
+func (c *Codec) decompressChunks(in []byte, chunkSizes []int32, chunkSize, sourceSize int) ([]byte, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf(
+			"invalid chunk size %d", chunkSize)
+	}
+
+	if expected := max((sourceSize+chunkSize-1)/chunkSize, 1); len(chunkSizes) != expected {
+		return nil, fmt.Errorf(
+			"expected %d chunks but got %d", expected, len(chunkSizes))
+	}
+
+	offsets := make([]int, len(chunkSizes)+1)
+
+	for i, size := range chunkSizes {
+		if size < 0 {
+			return nil, fmt.Errorf(
+				"invalid size %d of chunk %d", size, i)
+		}
+
+		offsets[i+1] = offsets[i] + int(size)
+	}
+
+	if offsets[len(chunkSizes)] != len(in) {
+		return nil, fmt.Errorf(
+			"chunks take %d bytes but the data is %d bytes long",
+			offsets[len(chunkSizes)], len(in))
+	}
+
+	source := make([]byte, sourceSize)
+
+	err := runParallel(len(chunkSizes), c.Workers, func(i int) error {
+		start := i * chunkSize
+		end := min(start+chunkSize, sourceSize)
+		chunk, err := c.Decompress(in[offsets[i]:offsets[i+1]], end-start)
+
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", i, err)
+		}
+
+		copy(source[start:end], chunk)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return source, nil
+}
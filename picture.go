@@ -4,15 +4,22 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"image"
-	"image/draw"
+	"image/color"
 	_ "image/jpeg"
 	_ "image/png"
 
 	"github.com/alacrity-engine/core/math/geometry"
 )
 
+var (
+	// ErrHashMismatch is returned when the hash of the
+	// decompressed picture differs from the recorded one.
+	ErrHashMismatch = errors.New("data corruption error")
+)
+
 type PictureData struct {
 	Width         int32
 	Height        int32
@@ -20,6 +27,9 @@ type PictureData struct {
 	Hash          []byte
 	PixFormat     PixFormat
 	HashAlgorithm HashAlgorithm
+	ColorSpace    ColorSpace
+	Palette       []color.NRGBA // Palette holds the colors the pixels of the PixFormatPaletted format refer to.
+	Origin        Origin
 }
 
 type CompressedPictureData struct {
@@ -31,112 +41,143 @@ type CompressedPictureData struct {
 	OriginalPixFormat     PixFormat
 	OriginalHashAlgorithm HashAlgorithm
 	CompressionAlgorithm  CompressionAlgorithm
+	PixFilter             PixFilter
+	ChunkSize             int32   // ChunkSize is the number of filtered pixel bytes per independently compressed chunk or zero.
+	CompressedChunkSizes  []int32 // CompressedChunkSizes are the sizes of the compressed chunks stored one after another in CompressedPix.
+	ColorSpace            ColorSpace
+	Palette               []color.NRGBA
+	Origin                Origin
+	Mipmaps               []*CompressedPictureData // Mipmaps are the independently compressed levels starting from the level 1.
 }
 
 // GetSpritesheetFrames returns the set of rectangles
-// corresponding to the frames of the spritesheet.
+// corresponding to the frames of the spritesheet
+// in the coordinates of the picture origin.
 func (pic *PictureData) GetSpritesheetFrames(ss *SpritesheetData) ([]geometry.Rect, error) {
-	orig := OrigData{
-		X: ss.Orig.X,
-		Y: ss.Orig.Y + ss.Area.PixelHeight,
-	}
+	return spritesheetFrames(pic.Width, pic.Height, pic.Origin, ss)
+}
 
-	if orig.X+ss.Area.PixelWidth > pic.Width ||
-		orig.Y-ss.Area.PixelHeight < 0 {
-		return nil, fmt.Errorf(
-			"the spritesheet cannot be applied to the picture")
-	}
+// GetSpritesheetFrames returns the set of rectangles
+// corresponding to the frames of the spritesheet
+// in the coordinates of the picture origin.
+func (cpic *CompressedPictureData) GetSpritesheetFrames(ss *SpritesheetData) ([]geometry.Rect, error) {
+	return spritesheetFrames(cpic.Width, cpic.Height, cpic.Origin, ss)
+}
 
-	frames := make([]geometry.Rect, 0)
-	pixelWidth := float64(ss.Area.PixelWidth)
-	pixelHeight := float64(ss.Area.PixelHeight)
-	dw := pixelWidth / float64(ss.Width)
-	dh := pixelHeight / float64(ss.Height)
+func (picture *PictureData) Compress() (*CompressedPictureData, error) {
+	return DefaultCodec().CompressPicture(picture)
+}
 
-	for y := float64(orig.Y); y > float64(ss.Orig.Y); y -= dh {
-		for x := float64(orig.X); x < float64(orig.X)+pixelWidth; x += dw {
-			frame := geometry.R(x, y-dh, x+dw, y)
-			frames = append(frames, frame)
-		}
-	}
+// CompressFiltered applies the filter to the
+// pixels of the picture and compresses them.
+func (picture *PictureData) CompressFiltered(filter PixFilter) (*CompressedPictureData, error) {
+	c := DefaultCodec()
+	c.PixFilter = filter
 
-	return frames, nil
+	return c.CompressPicture(picture)
 }
 
-// GetSpritesheetFrames returns the set of rectangles
-// corresponding to the frames of the spritesheet.
-func (cpic *CompressedPictureData) GetSpritesheetFrames(ss *SpritesheetData) ([]geometry.Rect, error) {
-	orig := OrigData{
-		X: ss.Orig.X,
-		Y: ss.Orig.Y + ss.Area.PixelHeight,
-	}
+// CompressBest compresses the picture with every
+// registered compression algorithm and keeps
+// the smallest output.
+func (picture *PictureData) CompressBest() (*CompressedPictureData, error) {
+	return DefaultCodec().CompressPictureBest(picture, 0)
+}
 
-	if orig.X+ss.Area.PixelWidth > cpic.Width ||
-		orig.Y-ss.Area.PixelHeight < 0 {
-		return nil, fmt.Errorf(
-			"the spritesheet cannot be applied to the picture")
-	}
+// CompressBestWeighted compresses the picture with every
+// registered compression algorithm and keeps the output
+// with the best size/speed score. speedWeight lies in [0; 1]
+// where 1 favours the decompression speed only.
+func (picture *PictureData) CompressBestWeighted(speedWeight float64) (*CompressedPictureData, error) {
+	return DefaultCodec().CompressPictureBest(picture, speedWeight)
+}
 
-	frames := make([]geometry.Rect, 0)
-	pixelWidth := float64(ss.Area.PixelWidth)
-	pixelHeight := float64(ss.Area.PixelHeight)
-	dw := pixelWidth / float64(ss.Width)
-	dh := pixelHeight / float64(ss.Height)
+// codec returns the codec configured with
+// the algorithms the picture was encoded with.
+func (compressedPicture *CompressedPictureData) codec() *Codec {
+	return NewCodec(
+		compressedPicture.OriginalHashAlgorithm,
+		compressedPicture.OriginalPixFormat,
+		compressedPicture.CompressionAlgorithm)
+}
 
-	for y := float64(orig.Y); y > float64(ss.Orig.Y); y -= dh {
-		for x := float64(orig.X); x < float64(orig.X)+pixelWidth; x += dw {
-			frame := geometry.R(x, y-dh, x+dw, y)
-			frames = append(frames, frame)
-		}
+func (compressedPicture *CompressedPictureData) Decompress() (*PictureData, error) {
+	if compressedPicture.Width < 0 || compressedPicture.Height < 0 ||
+		compressedPicture.OriginalPixSize < 0 {
+		return nil, fmt.Errorf(
+			"invalid picture size %dx%d of %d bytes",
+			compressedPicture.Width, compressedPicture.Height,
+			compressedPicture.OriginalPixSize)
 	}
 
-	return frames, nil
-}
-
-func (picture *PictureData) Compress() (*CompressedPictureData, error) {
-	compressedPix, err := Compress(picture.Pix)
+	pictureCodec := compressedPicture.codec()
+	hasher, err := pictureCodec.NewHasher()
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &CompressedPictureData{
-		Width:                 picture.Width,
-		Height:                picture.Height,
-		OriginalPixSize:       int32(len(picture.Pix)),
-		CompressedPix:         compressedPix,
-		OriginalHash:          picture.Hash,
-		OriginalPixFormat:     picture.PixFormat,
-		OriginalHashAlgorithm: picture.HashAlgorithm,
-		CompressionAlgorithm:  ConsentedCompressionAlgorithm,
-	}, nil
-}
+	// The palette is hashed
+	// before the indices.
+	_, err = hasher.Write(paletteBytes(compressedPicture.Palette))
 
-func (compressedPicture *CompressedPictureData) Decompress() (*PictureData, error) {
-	previousCompressionAlgorithm := ConsentedCompressionAlgorithm
-	defer func() { ConsentedCompressionAlgorithm = previousCompressionAlgorithm }()
-	ConsentedCompressionAlgorithm = compressedPicture.CompressionAlgorithm
+	if err != nil {
+		return nil, err
+	}
 
-	previousHashAlgorithm := ConsentedHashAlgorithm
-	defer func() { ConsentedHashAlgorithm = previousHashAlgorithm }()
-	ConsentedHashAlgorithm = compressedPicture.OriginalHashAlgorithm
+	filteredSize := filteredPixSize(int(compressedPicture.OriginalPixSize),
+		int(compressedPicture.Height), compressedPicture.PixFilter)
+	var filteredPix []byte
+	hashed := false
+
+	switch {
+	case compressedPicture.ChunkSize > 0:
+		filteredPix, err = pictureCodec.decompressChunks(compressedPicture.CompressedPix,
+			compressedPicture.CompressedChunkSizes,
+			int(compressedPicture.ChunkSize), filteredSize)
+
+	case compressedPicture.PixFilter == PixFilterNone:
+		// Hash the pixels as they come
+		// out of the decompressor.
+		filteredPix, hashed, err = pictureCodec.decompressHashing(
+			compressedPicture.CompressedPix, filteredSize, hasher)
+
+		if !hashed {
+			filteredPix, err = pictureCodec.Decompress(
+				compressedPicture.CompressedPix, filteredSize)
+		}
 
-	decompressedPix, err := Decompress(compressedPicture.CompressedPix,
-		int(compressedPicture.OriginalPixSize))
+	default:
+		filteredPix, err = pictureCodec.Decompress(
+			compressedPicture.CompressedPix, filteredSize)
+	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	decompressedHash, err := Hash(decompressedPix)
+	decompressedPix, err := unfilterPix(filteredPix,
+		int(compressedPicture.Width), int(compressedPicture.Height),
+		compressedPicture.OriginalPixFormat.BytesPerPixel(),
+		compressedPicture.PixFilter)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if !hashed {
+		_, err = hasher.Write(decompressedPix)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	decompressedHash := hasher.Sum(nil)
+
 	if !sliceEqual(decompressedHash, compressedPicture.OriginalHash) {
 		return nil, fmt.Errorf(
-			"data corruption error: expected %s but got %s (%s)",
+			"%w: expected %s but got %s (%s)", ErrHashMismatch,
 			hex.EncodeToString(compressedPicture.OriginalHash),
 			hex.EncodeToString(decompressedHash),
 			compressedPicture.OriginalHashAlgorithm)
@@ -149,13 +190,39 @@ func (compressedPicture *CompressedPictureData) Decompress() (*PictureData, erro
 		Hash:          decompressedHash,
 		PixFormat:     compressedPicture.OriginalPixFormat,
 		HashAlgorithm: compressedPicture.OriginalHashAlgorithm,
+		ColorSpace:    compressedPicture.ColorSpace,
+		Palette:       compressedPicture.Palette,
+		Origin:        compressedPicture.Origin,
 	}, nil
 }
 
+// DecompressAs decompresses the picture and
+// converts it to the given pixel format,
+// e.g. expands the paletted picture to RGBA.
+func (compressedPicture *CompressedPictureData) DecompressAs(pixFormat PixFormat) (*PictureData, error) {
+	picture, err := compressedPicture.Decompress()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if picture.PixFormat == pixFormat {
+		return picture, nil
+	}
+
+	return picture.ConvertTo(pixFormat)
+}
+
 func (compressedPicture *CompressedPictureData) ToBytes() ([]byte, error) {
 	buffer := bytes.NewBuffer([]byte{})
 
-	err := binary.Write(buffer, binary.BigEndian, compressedPicture.Width)
+	err := writeFormatHeader(buffer, compressedPictureFormatVersion)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(buffer, binary.BigEndian, compressedPicture.Width)
 
 	if err != nil {
 		return nil, err
@@ -215,62 +282,130 @@ func (compressedPicture *CompressedPictureData) ToBytes() ([]byte, error) {
 		return nil, err
 	}
 
-	return buffer.Bytes(), nil
-}
+	err = binary.Write(buffer, binary.BigEndian, int32(compressedPicture.PixFilter))
 
-func CompressedPictureFromBytes(data []byte) (*CompressedPictureData, error) {
-	buffer := bytes.NewBuffer(data)
-	compressedPicture := &CompressedPictureData{}
+	if err != nil {
+		return nil, err
+	}
 
-	err := binary.Read(buffer, binary.BigEndian, &compressedPicture.Width)
+	err = binary.Write(buffer, binary.BigEndian, compressedPicture.ChunkSize)
 
 	if err != nil {
 		return nil, err
 	}
 
-	err = binary.Read(buffer, binary.BigEndian, &compressedPicture.Height)
+	err = binary.Write(buffer, binary.BigEndian, int32(len(compressedPicture.CompressedChunkSizes)))
 
 	if err != nil {
 		return nil, err
 	}
 
-	err = binary.Read(buffer, binary.BigEndian, &compressedPicture.OriginalPixSize)
+	err = binary.Write(buffer, binary.BigEndian, compressedPicture.CompressedChunkSizes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(buffer, binary.BigEndian, int32(compressedPicture.ColorSpace))
 
 	if err != nil {
 		return nil, err
 	}
 
-	var compressedPixLength int32
-	err = binary.Read(buffer, binary.BigEndian, &compressedPixLength)
+	paletteData := paletteBytes(compressedPicture.Palette)
+	err = binary.Write(buffer, binary.BigEndian, int32(len(paletteData)))
 
 	if err != nil {
 		return nil, err
 	}
 
-	compressedPix := make([]byte, compressedPixLength)
-	_, err = buffer.Read(compressedPix)
+	_, err = buffer.Write(paletteData)
 
 	if err != nil {
 		return nil, err
 	}
 
-	compressedPicture.CompressedPix = compressedPix
+	err = binary.Write(buffer, binary.BigEndian, int32(compressedPicture.Origin))
+
+	if err != nil {
+		return nil, err
+	}
 
-	var originalHashLength int32
-	err = binary.Read(buffer, binary.BigEndian, &originalHashLength)
+	err = binary.Write(buffer, binary.BigEndian, int32(len(compressedPicture.Mipmaps)))
 
 	if err != nil {
 		return nil, err
 	}
 
-	originalHash := make([]byte, originalHashLength)
-	_, err = buffer.Read(originalHash)
+	for _, mipmap := range compressedPicture.Mipmaps {
+		mipmapData, err := mipmap.ToBytes()
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = binary.Write(buffer, binary.BigEndian, int32(len(mipmapData)))
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = buffer.Write(mipmapData)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func CompressedPictureFromBytes(data []byte) (*CompressedPictureData, error) {
+	buffer := bytes.NewBuffer(data)
+	compressedPicture := &CompressedPictureData{}
+
+	version, err := readFormatHeader(buffer, compressedPictureFormatVersion)
 
 	if err != nil {
 		return nil, err
 	}
 
-	compressedPicture.OriginalHash = originalHash
+	// The legacy data ends after the fields known
+	// at the moment it was serialized so the missing
+	// trailing ones keep their zero defaults.
+	absent := func() bool {
+		return version == 0 && buffer.Len() == 0
+	}
+
+	err = binary.Read(buffer, binary.BigEndian, &compressedPicture.Width)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Read(buffer, binary.BigEndian, &compressedPicture.Height)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Read(buffer, binary.BigEndian, &compressedPicture.OriginalPixSize)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compressedPicture.CompressedPix, err = readLengthPrefixed(buffer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compressedPicture.OriginalHash, err = readLengthPrefixed(buffer)
+
+	if err != nil {
+		return nil, err
+	}
 
 	var originalPixFormat int32
 	err = binary.Read(buffer, binary.BigEndian, &originalPixFormat)
@@ -299,29 +434,131 @@ func CompressedPictureFromBytes(data []byte) (*CompressedPictureData, error) {
 
 	compressedPicture.CompressionAlgorithm = CompressionAlgorithm(compressionAlgorithm)
 
-	return compressedPicture, nil
-}
+	if absent() {
+		return compressedPicture, nil
+	}
 
-func NewPictureFromImage(img image.Image) (*PictureData, error) {
-	imgRGBA := image.NewRGBA(image.Rect(0, 0,
-		img.Bounds().Dx(), img.Bounds().Dy()))
-	draw.Draw(imgRGBA, imgRGBA.Bounds(),
-		img.Bounds(), img.Bounds().Min, draw.Src)
-	reversePix(imgRGBA.Pix)
-	mirror(imgRGBA)
+	var pixFilter int32
+	err = binary.Read(buffer, binary.BigEndian, &pixFilter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compressedPicture.PixFilter = PixFilter(pixFilter)
 
-	hash, err := Hash(imgRGBA.Pix)
+	if absent() {
+		return compressedPicture, nil
+	}
+
+	err = binary.Read(buffer, binary.BigEndian, &compressedPicture.ChunkSize)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &PictureData{
-		Width:         int32(imgRGBA.Bounds().Dx()),
-		Height:        int32(imgRGBA.Bounds().Dy()),
-		Pix:           imgRGBA.Pix,
-		Hash:          hash,
-		PixFormat:     ConsentedPixFormat,
-		HashAlgorithm: ConsentedHashAlgorithm,
-	}, nil
+	var chunkCount int32
+	err = binary.Read(buffer, binary.BigEndian, &chunkCount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if chunkCount < 0 || int(chunkCount)*4 > buffer.Len() {
+		return nil, fmt.Errorf(
+			"invalid chunk count %d with %d bytes left",
+			chunkCount, buffer.Len())
+	}
+
+	if chunkCount > 0 {
+		compressedPicture.CompressedChunkSizes = make([]int32, chunkCount)
+		err = binary.Read(buffer, binary.BigEndian, compressedPicture.CompressedChunkSizes)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if absent() {
+		return compressedPicture, nil
+	}
+
+	var colorSpace int32
+	err = binary.Read(buffer, binary.BigEndian, &colorSpace)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compressedPicture.ColorSpace = ColorSpace(colorSpace)
+
+	if absent() {
+		return compressedPicture, nil
+	}
+
+	paletteData, err := readLengthPrefixed(buffer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paletteData)%4 != 0 || len(paletteData) > MaxPaletteSize*4 {
+		return nil, fmt.Errorf(
+			"invalid palette of %d bytes", len(paletteData))
+	}
+
+	if len(paletteData) > 0 {
+		compressedPicture.Palette = paletteFromBytes(paletteData)
+	}
+
+	if absent() {
+		return compressedPicture, nil
+	}
+
+	var origin int32
+	err = binary.Read(buffer, binary.BigEndian, &origin)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compressedPicture.Origin = Origin(origin)
+
+	if absent() {
+		return compressedPicture, nil
+	}
+
+	var mipmapCount int32
+	err = binary.Read(buffer, binary.BigEndian, &mipmapCount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if mipmapCount < 0 {
+		return nil, fmt.Errorf(
+			"invalid mipmap count %d", mipmapCount)
+	}
+
+	for i := int32(0); i < mipmapCount; i++ {
+		mipmapData, err := readLengthPrefixed(buffer)
+
+		if err != nil {
+			return nil, err
+		}
+
+		mipmap, err := CompressedPictureFromBytes(mipmapData)
+
+		if err != nil {
+			return nil, fmt.Errorf("mipmap level %d: %w", i+1, err)
+		}
+
+		compressedPicture.Mipmaps = append(compressedPicture.Mipmaps, mipmap)
+	}
+
+	return compressedPicture, nil
+}
+
+func NewPictureFromImage(img image.Image) (*PictureData, error) {
+	return DefaultCodec().NewPictureFromImage(img)
 }
@@ -0,0 +1,423 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image/color"
+)
+
+// pixCodec packs and unpacks a single pixel
+// of a format to and from the non-premultiplied
+// 16-bit RGBA every conversion goes through.
+type pixCodec struct {
+	decode func(src []byte) color.NRGBA64
+	encode func(dst []byte, c color.NRGBA64)
+}
+
+var pixCodecs = map[PixFormat]pixCodec{
+	PixFormatRGBA: {
+		decode: func(src []byte) color.NRGBA64 {
+			return color.NRGBA64{
+				R: widen8(src[0]),
+				G: widen8(src[1]),
+				B: widen8(src[2]),
+				A: widen8(src[3]),
+			}
+		},
+		encode: func(dst []byte, c color.NRGBA64) {
+			dst[0] = narrow16(c.R)
+			dst[1] = narrow16(c.G)
+			dst[2] = narrow16(c.B)
+			dst[3] = narrow16(c.A)
+		},
+	},
+	PixFormatRGB: {
+		decode: func(src []byte) color.NRGBA64 {
+			return color.NRGBA64{
+				R: widen8(src[0]),
+				G: widen8(src[1]),
+				B: widen8(src[2]),
+				A: 0xFFFF,
+			}
+		},
+		encode: func(dst []byte, c color.NRGBA64) {
+			dst[0] = narrow16(c.R)
+			dst[1] = narrow16(c.G)
+			dst[2] = narrow16(c.B)
+		},
+	},
+	PixFormatCMYK: {
+		decode: func(src []byte) color.NRGBA64 {
+			r, g, b, _ := color.CMYK{
+				C: src[0], M: src[1], Y: src[2], K: src[3],
+			}.RGBA()
+
+			return color.NRGBA64{
+				R: uint16(r),
+				G: uint16(g),
+				B: uint16(b),
+				A: 0xFFFF,
+			}
+		},
+		encode: func(dst []byte, c color.NRGBA64) {
+			dst[0], dst[1], dst[2], dst[3] = color.RGBToCMYK(
+				narrow16(c.R), narrow16(c.G), narrow16(c.B))
+		},
+	},
+	PixFormatL8: {
+		decode: func(src []byte) color.NRGBA64 {
+			l := widen8(src[0])
+
+			return color.NRGBA64{R: l, G: l, B: l, A: 0xFFFF}
+		},
+		encode: func(dst []byte, c color.NRGBA64) {
+			dst[0] = narrow16(luminance(c))
+		},
+	},
+	PixFormatLA8: {
+		decode: func(src []byte) color.NRGBA64 {
+			l := widen8(src[0])
+
+			return color.NRGBA64{R: l, G: l, B: l, A: widen8(src[1])}
+		},
+		encode: func(dst []byte, c color.NRGBA64) {
+			dst[0] = narrow16(luminance(c))
+			dst[1] = narrow16(c.A)
+		},
+	},
+	PixFormatRGB565: {
+		decode: func(src []byte) color.NRGBA64 {
+			v := binary.BigEndian.Uint16(src)
+
+			return color.NRGBA64{
+				R: expandBits(v>>11, 5),
+				G: expandBits(v>>5&0x3F, 6),
+				B: expandBits(v&0x1F, 5),
+				A: 0xFFFF,
+			}
+		},
+		encode: func(dst []byte, c color.NRGBA64) {
+			binary.BigEndian.PutUint16(dst,
+				quantizeBits(c.R, 5)<<11|
+					quantizeBits(c.G, 6)<<5|
+					quantizeBits(c.B, 5))
+		},
+	},
+	PixFormatRGBA4444: {
+		decode: func(src []byte) color.NRGBA64 {
+			v := binary.BigEndian.Uint16(src)
+
+			return color.NRGBA64{
+				R: expandBits(v>>12, 4),
+				G: expandBits(v>>8&0xF, 4),
+				B: expandBits(v>>4&0xF, 4),
+				A: expandBits(v&0xF, 4),
+			}
+		},
+		encode: func(dst []byte, c color.NRGBA64) {
+			binary.BigEndian.PutUint16(dst,
+				quantizeBits(c.R, 4)<<12|
+					quantizeBits(c.G, 4)<<8|
+					quantizeBits(c.B, 4)<<4|
+					quantizeBits(c.A, 4))
+		},
+	},
+	PixFormatRGBA64: {
+		decode: func(src []byte) color.NRGBA64 {
+			return color.NRGBA64{
+				R: binary.BigEndian.Uint16(src[0:]),
+				G: binary.BigEndian.Uint16(src[2:]),
+				B: binary.BigEndian.Uint16(src[4:]),
+				A: binary.BigEndian.Uint16(src[6:]),
+			}
+		},
+		encode: func(dst []byte, c color.NRGBA64) {
+			binary.BigEndian.PutUint16(dst[0:], c.R)
+			binary.BigEndian.PutUint16(dst[2:], c.G)
+			binary.BigEndian.PutUint16(dst[4:], c.B)
+			binary.BigEndian.PutUint16(dst[6:], c.A)
+		},
+	},
+	PixFormatRGBAPremultiplied: {
+		decode: func(src []byte) color.NRGBA64 {
+			a := widen8(src[3])
+
+			return color.NRGBA64{
+				R: unpremultiply(widen8(src[0]), a),
+				G: unpremultiply(widen8(src[1]), a),
+				B: unpremultiply(widen8(src[2]), a),
+				A: a,
+			}
+		},
+		encode: func(dst []byte, c color.NRGBA64) {
+			dst[0] = narrow16(premultiply(c.R, c.A))
+			dst[1] = narrow16(premultiply(c.G, c.A))
+			dst[2] = narrow16(premultiply(c.B, c.A))
+			dst[3] = narrow16(c.A)
+		},
+	},
+}
+
+// widen8 expands the 8-bit
+// channel value to 16 bits.
+func widen8(v uint8) uint16 {
+	return uint16(v) * 0x101
+}
+
+// narrow16 truncates the 16-bit channel value
+// to 8 bits the way the image/color models do.
+func narrow16(v uint16) uint8 {
+	return uint8(v >> 8)
+}
+
+// quantizeBits rounds the 16-bit
+// channel value to the given number of bits.
+func quantizeBits(v uint16, bits uint) uint16 {
+	maxValue := uint32(1)<<bits - 1
+
+	return uint16((uint32(v)*maxValue + 0x7FFF) / 0xFFFF)
+}
+
+// expandBits scales the channel value of the
+// given number of bits to 16 bits.
+func expandBits(v uint16, bits uint) uint16 {
+	maxValue := uint32(1)<<bits - 1
+
+	return uint16(uint32(v) * 0xFFFF / maxValue)
+}
+
+// premultiply multiplies the 16-bit
+// channel value by the alpha.
+func premultiply(v, a uint16) uint16 {
+	return uint16(uint32(v) * uint32(a) / 0xFFFF)
+}
+
+// unpremultiply divides the premultiplied
+// 16-bit channel value by the alpha.
+func unpremultiply(v, a uint16) uint16 {
+	if a == 0 {
+		return 0
+	}
+
+	return uint16(min(uint32(v)*0xFFFF/uint32(a), 0xFFFF))
+}
+
+// luminance computes the luminance of the color
+// with the coefficients of color.GrayModel.
+func luminance(c color.NRGBA64) uint16 {
+	return uint16((19595*uint32(c.R) + 38470*uint32(c.G) +
+		7471*uint32(c.B) + 1<<15) >> 16)
+}
+
+// convertPix repacks the pixels from one format
+// to another. The alpha channel is dropped when
+// the target format has none.
+func convertPix(pix []byte, from, to PixFormat) ([]byte, error) {
+	if from == to {
+		return append([]byte{}, pix...), nil
+	}
+
+	fromCodec, ok := pixCodecs[from]
+
+	if !ok {
+		return nil, fmt.Errorf(
+			"no conversion from pixel format %d", from)
+	}
+
+	toCodec, ok := pixCodecs[to]
+
+	if !ok {
+		return nil, fmt.Errorf(
+			"no conversion to pixel format %d", to)
+	}
+
+	fromSize := from.BytesPerPixel()
+	toSize := to.BytesPerPixel()
+
+	if len(pix)%fromSize != 0 {
+		return nil, fmt.Errorf(
+			"%d bytes are not whole pixels of format '%s'",
+			len(pix), from)
+	}
+
+	pixelCount := len(pix) / fromSize
+	converted := make([]byte, pixelCount*toSize)
+
+	for i := 0; i < pixelCount; i++ {
+		toCodec.encode(converted[i*toSize:(i+1)*toSize],
+			fromCodec.decode(pix[i*fromSize:(i+1)*fromSize]))
+	}
+
+	return converted, nil
+}
+
+// mapPix applies the function to every pixel
+// of the given format and repacks the result
+// into the same format.
+func mapPix(pix []byte, pixFormat PixFormat, f func(color.NRGBA64) color.NRGBA64) ([]byte, error) {
+	pixCodec, ok := pixCodecs[pixFormat]
+
+	if !ok {
+		return nil, fmt.Errorf(
+			"no conversion from pixel format %d", pixFormat)
+	}
+
+	size := pixFormat.BytesPerPixel()
+
+	if len(pix)%size != 0 {
+		return nil, fmt.Errorf(
+			"%d bytes are not whole pixels of format '%s'",
+			len(pix), pixFormat)
+	}
+
+	mapped := make([]byte, len(pix))
+
+	for i := 0; i < len(pix); i += size {
+		pixCodec.encode(mapped[i:i+size], f(pixCodec.decode(pix[i:i+size])))
+	}
+
+	return mapped, nil
+}
+
+// ConvertTo returns a new picture with the pixels
+// repacked into the given format and the hash
+// recomputed with the hash algorithm of the picture.
+func (picture *PictureData) ConvertTo(pixFormat PixFormat) (*PictureData, error) {
+	if picture.PixFormat != pixFormat {
+		switch {
+		case picture.PixFormat == PixFormatPaletted,
+			isBlockCompressed(picture.PixFormat):
+			rgbaPicture, err := picture.decodeRGBA()
+
+			if err != nil || pixFormat == PixFormatRGBA {
+				return rgbaPicture, err
+			}
+
+			return rgbaPicture.ConvertTo(pixFormat)
+
+		case pixFormat == PixFormatPaletted:
+			return picture.Quantize(MaxPaletteSize, false)
+
+		case isBlockCompressed(pixFormat):
+			return picture.encodeBlocks(pixFormat)
+		}
+	}
+
+	pix, err := convertPix(picture.Pix, picture.PixFormat, pixFormat)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return picture.withPix(pix, pixFormat)
+}
+
+// toRGBA64 converts the picture to the PixFormatRGBA64
+// format making sure the converted pixels cover
+// the whole picture.
+func (picture *PictureData) toRGBA64() (*PictureData, error) {
+	widePicture, err := picture.ConvertTo(PixFormatRGBA64)
+
+	if err != nil {
+		return nil, err
+	}
+
+	width := int(widePicture.Width)
+	height := int(widePicture.Height)
+
+	if len(widePicture.Pix) != PixFormatRGBA64.PixSize(width, height) {
+		return nil, fmt.Errorf(
+			"pixel data of %d bytes doesn't match the %dx%d picture of format '%s'",
+			len(widePicture.Pix), width, height, PixFormatRGBA64)
+	}
+
+	return widePicture, nil
+}
+
+// withPix returns a copy of the picture with
+// the new pixels of the given format hashed
+// with the hash algorithm of the picture.
+func (picture *PictureData) withPix(pix []byte, pixFormat PixFormat) (*PictureData, error) {
+	var palette []color.NRGBA
+
+	if pixFormat == PixFormatPaletted {
+		palette = picture.Palette
+	}
+
+	hashCodec := DefaultCodec()
+	hashCodec.HashAlgorithm = picture.HashAlgorithm
+	hash, err := hashCodec.hashPicture(palette, pix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PictureData{
+		Width:         picture.Width,
+		Height:        picture.Height,
+		Pix:           pix,
+		Hash:          hash,
+		PixFormat:     pixFormat,
+		HashAlgorithm: picture.HashAlgorithm,
+		ColorSpace:    picture.ColorSpace,
+		Palette:       palette,
+		Origin:        picture.Origin,
+	}, nil
+}
+
+// decodeRGBA returns a new picture with the
+// pixels of the paletted or block-compressed
+// picture decoded to RGBA.
+func (picture *PictureData) decodeRGBA() (*PictureData, error) {
+	var pix []byte
+	var err error
+
+	if picture.PixFormat == PixFormatPaletted {
+		pix, err = expandPalette(picture.Pix, picture.Palette)
+	} else {
+		pix, err = decodeBlocks(picture.Pix, int(picture.Width),
+			int(picture.Height), picture.PixFormat)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return picture.withPix(pix, PixFormatRGBA)
+}
+
+// encodeBlocks returns a new picture with the
+// pixels encoded into the block-compressed format.
+func (picture *PictureData) encodeBlocks(pixFormat PixFormat) (*PictureData, error) {
+	rgbaPicture, err := picture.ConvertTo(PixFormatRGBA)
+
+	if err != nil {
+		return nil, err
+	}
+
+	width := int(picture.Width)
+	height := int(picture.Height)
+
+	if len(rgbaPicture.Pix) != PixFormatRGBA.PixSize(width, height) {
+		return nil, fmt.Errorf(
+			"pixel data of %d bytes doesn't match the %dx%d picture",
+			len(rgbaPicture.Pix), width, height)
+	}
+
+	return picture.withPix(encodeBlocks(rgbaPicture.Pix,
+		width, height, pixFormat), pixFormat)
+}
+
+// Premultiply returns a new picture with the color
+// channels multiplied by alpha in the
+// PixFormatRGBAPremultiplied format.
+func (picture *PictureData) Premultiply() (*PictureData, error) {
+	return picture.ConvertTo(PixFormatRGBAPremultiplied)
+}
+
+// Unpremultiply returns a new picture with the
+// premultiplied color channels divided by alpha
+// in the PixFormatRGBA format.
+func (picture *PictureData) Unpremultiply() (*PictureData, error) {
+	return picture.ConvertTo(PixFormatRGBA)
+}
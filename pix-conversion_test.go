@@ -0,0 +1,154 @@
+package codec_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertPicture(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(t, err)
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+
+	rgbPicture, err := picture.ConvertTo(codec.PixFormatRGB)
+	assert.Nil(t, err)
+	assert.Equal(t, codec.PixFormatRGB, rgbPicture.PixFormat)
+	assert.Equal(t, len(picture.Pix)/4*3, len(rgbPicture.Pix))
+
+	rgbaPicture, err := rgbPicture.ConvertTo(codec.PixFormatRGBA)
+	assert.Nil(t, err)
+	assert.Equal(t, picture.Pix, rgbaPicture.Pix)
+	assert.Equal(t, picture.Hash, rgbaPicture.Hash)
+
+	rgbCodec := codec.NewCodec(codec.HashAlgorithmKeccak256,
+		codec.PixFormatRGB, codec.CompressionAlgorithmLZWOrderLSBLitWidth8)
+	rgbImagePicture, err := rgbCodec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+	assert.Equal(t, rgbPicture.Pix, rgbImagePicture.Pix)
+	assert.Equal(t, rgbPicture.Hash, rgbImagePicture.Hash)
+
+	// The directly packed 8-bit pixels
+	// match the converted ones.
+	l8Picture, err := picture.ConvertTo(codec.PixFormatL8)
+	assert.Nil(t, err)
+	rgbCodec.PixFormat = codec.PixFormatL8
+	l8ImagePicture, err := rgbCodec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+	assert.Equal(t, l8Picture.Pix, l8ImagePicture.Pix)
+
+	cmykPicture, err := picture.ConvertTo(codec.PixFormatCMYK)
+	assert.Nil(t, err)
+	restoredPicture, err := cmykPicture.ConvertTo(codec.PixFormatRGBA)
+	assert.Nil(t, err)
+
+	maxDelta := 0
+
+	for i := range picture.Pix {
+		maxDelta = max(maxDelta, abs(int(picture.Pix[i])-int(restoredPicture.Pix[i])))
+	}
+
+	assert.LessOrEqual(t, maxDelta, 3)
+}
+
+func TestNewPictureFromImageBounds(t *testing.T) {
+	// The image doesn't start at the origin
+	// and has the translucent pixels which
+	// must keep their straight alpha.
+	img := image.NewNRGBA(image.Rect(5, 5, 7, 7))
+	img.SetNRGBA(5, 5, color.NRGBA{R: 200, G: 100, B: 50, A: 128})
+	img.SetNRGBA(6, 5, color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+	img.SetNRGBA(5, 6, color.NRGBA{R: 255, A: 1})
+
+	c := codec.DefaultCodec()
+	c.Origin = codec.OriginTopLeft
+	picture, err := c.NewPictureFromImage(img)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), picture.Width)
+	assert.Equal(t, int32(2), picture.Height)
+	assert.Equal(t, img.Pix, picture.Pix)
+
+	// The bottom-left origin
+	// stores the rows bottom-up.
+	picture, err = codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+	assert.Equal(t, append(append([]byte{}, img.Pix[8:]...), img.Pix[:8]...),
+		picture.Pix)
+}
+
+func TestCompressPixFormats(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(t, err)
+	pixFormats := []codec.PixFormat{
+		codec.PixFormatL8,
+		codec.PixFormatLA8,
+		codec.PixFormatRGB565,
+		codec.PixFormatRGBA4444,
+		codec.PixFormatRGBA64,
+	}
+
+	for _, pixFormat := range pixFormats {
+		c := codec.NewCodec(codec.HashAlgorithmSHA256,
+			pixFormat, codec.CompressionAlgorithmDeflate)
+		picture, err := c.NewPictureFromImage(img)
+		assert.Nil(t, err)
+
+		pixSize := pixFormat.PixSize(img.Bounds().Dx(), img.Bounds().Dy())
+		assert.Equal(t, pixSize, len(picture.Pix), pixFormat.String())
+
+		compressedPicture, err := c.CompressPicture(picture)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(pixSize), compressedPicture.OriginalPixSize)
+
+		decompressedPicture, err := compressedPicture.Decompress()
+		assert.Nil(t, err)
+		assert.Equal(t, pixFormat, decompressedPicture.PixFormat)
+		assert.Equal(t, picture.Hash, decompressedPicture.Hash)
+
+		widePicture, err := decompressedPicture.ConvertTo(codec.PixFormatRGBA64)
+		assert.Nil(t, err)
+		convertedPicture, err := widePicture.ConvertTo(pixFormat)
+		assert.Nil(t, err)
+		assert.Equal(t, picture.Pix, convertedPicture.Pix)
+	}
+}
+
+func TestPremultiplyPicture(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 1))
+
+	for x := 0; x < 256; x++ {
+		img.SetNRGBA(x, 0, color.NRGBA{R: 255, G: 128, B: uint8(x), A: uint8(x)})
+	}
+
+	imgRGBA := image.NewRGBA(img.Bounds())
+	draw.Draw(imgRGBA, imgRGBA.Bounds(), img, image.Point{}, draw.Src)
+
+	c := codec.NewCodec(codec.HashAlgorithmSHA256,
+		codec.PixFormatRGBAPremultiplied, codec.CompressionAlgorithmDeflate)
+	premultipliedPicture, err := c.NewPictureFromImage(img)
+	assert.Nil(t, err)
+	assert.Equal(t, imgRGBA.Pix, premultipliedPicture.Pix)
+
+	hash, err := c.Hash(premultipliedPicture.Pix)
+	assert.Nil(t, err)
+	assert.Equal(t, hash, premultipliedPicture.Hash)
+
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+	assert.Equal(t, img.Pix, picture.Pix)
+	convertedPicture, err := picture.Premultiply()
+	assert.Nil(t, err)
+	assert.Equal(t, premultipliedPicture.Pix, convertedPicture.Pix)
+
+	unpremultipliedPicture, err := premultipliedPicture.Unpremultiply()
+	assert.Nil(t, err)
+	assert.Equal(t, codec.PixFormatRGBA, unpremultipliedPicture.PixFormat)
+	assert.Equal(t, img.Pix[len(img.Pix)-4:],
+		unpremultipliedPicture.Pix[len(img.Pix)-4:])
+}
@@ -0,0 +1,42 @@
+package codec
+
+type PixFilter int
+
+const (
+	PixFilterNone     PixFilter = iota // PixFilterNone leaves the pixels as they are.
+	PixFilterSub                       // PixFilterSub predicts each byte from the same channel of the pixel to the left.
+	PixFilterUp                        // PixFilterUp predicts each byte from the same channel of the pixel in the previous row.
+	PixFilterAverage                   // PixFilterAverage predicts each byte from the mean of the left and the upper pixels.
+	PixFilterPaeth                     // PixFilterPaeth predicts each byte with the Paeth predictor.
+	PixFilterAdaptive                  // PixFilterAdaptive picks the best prediction filter for each row separately.
+	PixFilterPlanar                    // PixFilterPlanar splits the pixels into separate channel planes.
+)
+
+// String returns the name of the pixel filter.
+func (filter PixFilter) String() string {
+	switch filter {
+	case PixFilterNone:
+		return "None"
+
+	case PixFilterSub:
+		return "Sub"
+
+	case PixFilterUp:
+		return "Up"
+
+	case PixFilterAverage:
+		return "Average"
+
+	case PixFilterPaeth:
+		return "Paeth"
+
+	case PixFilterAdaptive:
+		return "Adaptive"
+
+	case PixFilterPlanar:
+		return "Planar"
+
+	default:
+		return ""
+	}
+}
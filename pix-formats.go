@@ -3,9 +3,18 @@ package codec
 type PixFormat int
 
 const (
-	PixFormatRGBA PixFormat = iota
-	PixFormatRGB
-	PixFormatCMYK
+	PixFormatRGBA              PixFormat = iota // PixFormatRGBA stores 8-bit channels with non-premultiplied alpha.
+	PixFormatRGB                                // PixFormatRGB stores 8-bit channels without alpha.
+	PixFormatCMYK                               // PixFormatCMYK stores 8-bit ink channels without alpha.
+	PixFormatL8                                 // PixFormatL8 stores the 8-bit luminance.
+	PixFormatLA8                                // PixFormatLA8 stores the 8-bit luminance and non-premultiplied alpha.
+	PixFormatRGB565                             // PixFormatRGB565 packs 5-bit red, 6-bit green and 5-bit blue into a big-endian uint16.
+	PixFormatRGBA4444                           // PixFormatRGBA4444 packs 4-bit channels with non-premultiplied alpha into a big-endian uint16.
+	PixFormatRGBA64                             // PixFormatRGBA64 stores big-endian 16-bit channels with non-premultiplied alpha.
+	PixFormatRGBAPremultiplied                  // PixFormatRGBAPremultiplied stores 8-bit color channels multiplied by alpha.
+	PixFormatPaletted                           // PixFormatPaletted stores 8-bit indices into the palette of the picture.
+	PixFormatBC1                                // PixFormatBC1 stores 4×4 blocks of the DXT1 format with 1-bit alpha.
+	PixFormatBC3                                // PixFormatBC3 stores 4×4 blocks of the DXT5 format with interpolated alpha.
 )
 
 func (pixFormat PixFormat) String() string {
@@ -19,7 +28,73 @@ func (pixFormat PixFormat) String() string {
 	case PixFormatCMYK:
 		return "CMYK"
 
+	case PixFormatL8:
+		return "L8"
+
+	case PixFormatLA8:
+		return "LA8"
+
+	case PixFormatRGB565:
+		return "RGB565"
+
+	case PixFormatRGBA4444:
+		return "RGBA4444"
+
+	case PixFormatRGBA64:
+		return "RGBA64"
+
+	case PixFormatRGBAPremultiplied:
+		return "RGBAPremultiplied"
+
+	case PixFormatPaletted:
+		return "Paletted"
+
+	case PixFormatBC1:
+		return "BC1"
+
+	case PixFormatBC3:
+		return "BC3"
+
 	default:
 		return ""
 	}
 }
+
+// BytesPerPixel returns the number of bytes
+// a single pixel occupies in the format.
+// The block-compressed formats have no
+// whole number of bytes per pixel.
+func (pixFormat PixFormat) BytesPerPixel() int {
+	switch pixFormat {
+	case PixFormatRGBA, PixFormatCMYK, PixFormatRGBAPremultiplied:
+		return 4
+
+	case PixFormatRGB:
+		return 3
+
+	case PixFormatLA8, PixFormatRGB565, PixFormatRGBA4444:
+		return 2
+
+	case PixFormatL8, PixFormatPaletted:
+		return 1
+
+	case PixFormatRGBA64:
+		return 8
+
+	default:
+		return 0
+	}
+}
+
+// PixSize returns the number of bytes the pixels
+// of the width×height picture occupy in the format.
+func (pixFormat PixFormat) PixSize(width, height int) int {
+	if isBlockCompressed(pixFormat) {
+		columns := (width + blockSize - 1) / blockSize
+		rows := (height + blockSize - 1) / blockSize
+
+		return columns * rows * blockBytes(pixFormat)
+	}
+
+	return width * height * pixFormat.BytesPerPixel()
+}
@@ -0,0 +1,371 @@
+package codec
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// ResampleFilter is the kernel
+// the pictures are resized with.
+type ResampleFilter int
+
+const (
+	ResampleFilterNearest  ResampleFilter = iota // ResampleFilterNearest picks the closest source pixel.
+	ResampleFilterBilinear                       // ResampleFilterBilinear uses the triangle kernel.
+	ResampleFilterBicubic                        // ResampleFilterBicubic uses the Catmull-Rom kernel.
+	ResampleFilterLanczos                        // ResampleFilterLanczos uses the 3-lobe Lanczos kernel.
+)
+
+func (filter ResampleFilter) String() string {
+	switch filter {
+	case ResampleFilterNearest:
+		return "nearest"
+
+	case ResampleFilterBilinear:
+		return "bilinear"
+
+	case ResampleFilterBicubic:
+		return "bicubic"
+
+	case ResampleFilterLanczos:
+		return "Lanczos"
+
+	default:
+		return ""
+	}
+}
+
+// radius returns the support of the kernel.
+func (filter ResampleFilter) radius() float64 {
+	switch filter {
+	case ResampleFilterBilinear:
+		return 1
+
+	case ResampleFilterBicubic:
+		return 2
+
+	case ResampleFilterLanczos:
+		return 3
+
+	default:
+		return 0
+	}
+}
+
+// kernel evaluates the kernel
+// of the filter at x.
+func (filter ResampleFilter) kernel(x float64) float64 {
+	x = math.Abs(x)
+
+	switch filter {
+	case ResampleFilterBilinear:
+		if x < 1 {
+			return 1 - x
+		}
+
+	case ResampleFilterBicubic:
+		if x < 1 {
+			return (1.5*x-2.5)*x*x + 1
+		}
+
+		if x < 2 {
+			return ((-0.5*x+2.5)*x-4)*x + 2
+		}
+
+	case ResampleFilterLanczos:
+		if x == 0 {
+			return 1
+		}
+
+		if x < 3 {
+			return 3 * math.Sin(math.Pi*x) * math.Sin(math.Pi*x/3) /
+				(math.Pi * math.Pi * x * x)
+		}
+	}
+
+	return 0
+}
+
+// contribution is the set of the source
+// pixels a destination pixel is made of.
+type contribution struct {
+	first   int
+	weights []float32
+}
+
+// contributions computes the normalized weights of
+// the source pixels for every destination pixel
+// when resizing from srcSize to dstSize.
+func (filter ResampleFilter) contributions(srcSize, dstSize int) []contribution {
+	scale := float64(srcSize) / float64(dstSize)
+	contributions := make([]contribution, dstSize)
+
+	for i := range contributions {
+		center := (float64(i)+0.5)*scale - 0.5
+
+		if filter == ResampleFilterNearest {
+			nearest := min(int((float64(i)+0.5)*scale), srcSize-1)
+			contributions[i] = contribution{
+				first:   nearest,
+				weights: []float32{1},
+			}
+
+			continue
+		}
+
+		// Stretch the kernel when downscaling
+		// so it covers all the source pixels.
+		stretch := max(scale, 1)
+		support := filter.radius() * stretch
+		first := max(int(math.Ceil(center-support)), 0)
+		last := min(int(math.Floor(center+support)), srcSize-1)
+		kernelValues := make([]float64, 0, last-first+1)
+		sum := 0.0
+
+		for j := first; j <= last; j++ {
+			value := filter.kernel((float64(j) - center) / stretch)
+			kernelValues = append(kernelValues, value)
+			sum += value
+		}
+
+		weights := make([]float32, len(kernelValues))
+
+		for j, value := range kernelValues {
+			if sum != 0 {
+				value /= sum
+			}
+
+			weights[j] = float32(value)
+		}
+
+		contributions[i] = contribution{
+			first:   first,
+			weights: weights,
+		}
+	}
+
+	return contributions
+}
+
+// Resize returns a new picture of the given size
+// resampled with the filter. The colors are filtered
+// premultiplied by alpha so the transparent pixels
+// don't bleed into the opaque ones. The result has
+// the pixel format of the picture; the paletted
+// picture resized with the nearest filter keeps
+// its palette while the other filters quantize
+// the resampled colors anew.
+//
+// The source rows are resampled one at a time and
+// only the few of them the current destination row
+// is made of are kept in memory, 16 bytes per
+// destination pixel each.
+func (picture *PictureData) Resize(width, height int32, filter ResampleFilter) (*PictureData, error) {
+	if filter < ResampleFilterNearest || filter > ResampleFilterLanczos {
+		return nil, fmt.Errorf("unknown resample filter %d", filter)
+	}
+
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf(
+			"invalid target size %dx%d", width, height)
+	}
+
+	if picture.Width <= 0 || picture.Height <= 0 {
+		return nil, fmt.Errorf(
+			"invalid picture size %dx%d", picture.Width, picture.Height)
+	}
+
+	pixSize := picture.PixFormat.PixSize(int(picture.Width), int(picture.Height))
+
+	if len(picture.Pix) != pixSize {
+		return nil, fmt.Errorf(
+			"pixel data of %d bytes doesn't match the %dx%d picture of format '%s'",
+			len(picture.Pix), picture.Width, picture.Height, picture.PixFormat)
+	}
+
+	if filter == ResampleFilterNearest && picture.PixFormat.BytesPerPixel() > 0 {
+		return picture.resizeNearest(width, height)
+	}
+
+	// The paletted and block-compressed
+	// pixels are resampled as RGBA.
+	source := picture
+
+	if _, ok := pixCodecs[picture.PixFormat]; !ok {
+		rgbaPicture, err := picture.decodeRGBA()
+
+		if err != nil {
+			return nil, err
+		}
+
+		source = rgbaPicture
+	}
+
+	resized := *source
+	resized.Width = width
+	resized.Height = height
+	resizedPicture, err := resized.withPix(
+		resamplePix(source.Pix, int(source.Width), int(source.Height),
+			int(width), int(height), source.PixFormat, filter),
+		source.PixFormat)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resizedPicture.PixFormat == picture.PixFormat {
+		return resizedPicture, nil
+	}
+
+	return resizedPicture.ConvertTo(picture.PixFormat)
+}
+
+// resizeNearest resizes the picture copying the
+// closest source pixels as they are, so the pixel
+// format and the palette are kept intact.
+func (picture *PictureData) resizeNearest(width, height int32) (*PictureData, error) {
+	bytesPerPixel := picture.PixFormat.BytesPerPixel()
+	srcStride := int(picture.Width) * bytesPerPixel
+	dstStride := int(width) * bytesPerPixel
+	columns := ResampleFilterNearest.contributions(int(picture.Width), int(width))
+	rows := ResampleFilterNearest.contributions(int(picture.Height), int(height))
+	pix := make([]byte, dstStride*int(height))
+
+	for y, row := range rows {
+		for x, column := range columns {
+			src := row.first*srcStride + column.first*bytesPerPixel
+			dst := y*dstStride + x*bytesPerPixel
+			copy(pix[dst:dst+bytesPerPixel], picture.Pix[src:src+bytesPerPixel])
+		}
+	}
+
+	resized := *picture
+	resized.Width = width
+	resized.Height = height
+
+	return resized.withPix(pix, picture.PixFormat)
+}
+
+// resamplePix resamples the srcWidth×srcHeight pixels
+// of the format to dstWidth×dstHeight. Every source
+// row is unpacked and resampled horizontally once
+// and kept only while the destination rows being
+// resampled vertically refer to it.
+func resamplePix(pix []byte, srcWidth, srcHeight, dstWidth, dstHeight int, pixFormat PixFormat, filter ResampleFilter) []byte {
+	pixCodec := pixCodecs[pixFormat]
+	bytesPerPixel := pixFormat.BytesPerPixel()
+	columns := filter.contributions(srcWidth, dstWidth)
+	rows := filter.contributions(srcHeight, dstHeight)
+
+	srcRow := make([]float32, srcWidth*4)
+	dstRow := make([]float32, dstWidth*4)
+	resampledRows := map[int][]float32{}
+	spareRows := [][]float32{}
+	dst := make([]byte, pixFormat.PixSize(dstWidth, dstHeight))
+
+	for y, row := range rows {
+		// Release the rows the rest of
+		// the destination rows don't need.
+		for sy, resampledRow := range resampledRows {
+			if sy < row.first {
+				delete(resampledRows, sy)
+				spareRows = append(spareRows, resampledRow)
+			}
+		}
+
+		clear(dstRow)
+
+		for k, weight := range row.weights {
+			sy := row.first + k
+			resampledRow, ok := resampledRows[sy]
+
+			if !ok {
+				if len(spareRows) > 0 {
+					resampledRow = spareRows[len(spareRows)-1]
+					spareRows = spareRows[:len(spareRows)-1]
+				} else {
+					resampledRow = make([]float32, dstWidth*4)
+				}
+
+				unpackRow(srcRow, pix[sy*srcWidth*bytesPerPixel:(sy+1)*srcWidth*bytesPerPixel],
+					pixCodec, bytesPerPixel)
+				resampleRow(resampledRow, srcRow, columns)
+				resampledRows[sy] = resampledRow
+			}
+
+			for i, v := range resampledRow {
+				dstRow[i] += v * weight
+			}
+		}
+
+		packRow(dst[y*dstWidth*bytesPerPixel:(y+1)*dstWidth*bytesPerPixel],
+			dstRow, pixCodec, bytesPerPixel)
+	}
+
+	return dst
+}
+
+// unpackRow decodes the row of pixels into
+// the premultiplied floating point channels.
+func unpackRow(dst []float32, pix []byte, pixCodec pixCodec, bytesPerPixel int) {
+	for x := 0; x*bytesPerPixel < len(pix); x++ {
+		c := pixCodec.decode(pix[x*bytesPerPixel:])
+		a := float32(c.A) / 0xFFFF
+		dst[x*4] = float32(c.R) / 0xFFFF * a
+		dst[x*4+1] = float32(c.G) / 0xFFFF * a
+		dst[x*4+2] = float32(c.B) / 0xFFFF * a
+		dst[x*4+3] = a
+	}
+}
+
+// resampleRow resamples the row of the premultiplied
+// channels with the contributions of the columns.
+func resampleRow(dst, src []float32, columns []contribution) {
+	for x, column := range columns {
+		var sums [4]float32
+
+		for k, weight := range column.weights {
+			offset := (column.first + k) * 4
+
+			for ch := 0; ch < 4; ch++ {
+				sums[ch] += src[offset+ch] * weight
+			}
+		}
+
+		copy(dst[x*4:x*4+4], sums[:])
+	}
+}
+
+// packRow encodes the row of the premultiplied
+// floating point channels into the pixels.
+func packRow(pix []byte, src []float32, pixCodec pixCodec, bytesPerPixel int) {
+	for x := 0; x*bytesPerPixel < len(pix); x++ {
+		a := clamp01(src[x*4+3])
+		var channels [3]uint16
+
+		for ch := range channels {
+			if a > 0 {
+				channels[ch] = toUint16(clamp01(src[x*4+ch] / a))
+			}
+		}
+
+		pixCodec.encode(pix[x*bytesPerPixel:], color.NRGBA64{
+			R: channels[0],
+			G: channels[1],
+			B: channels[2],
+			A: toUint16(a),
+		})
+	}
+}
+
+// toUint16 scales the value
+// of [0; 1] to 16 bits.
+func toUint16(v float32) uint16 {
+	return uint16(v*0xFFFF + 0.5)
+}
+
+// clamp01 clamps the value to [0; 1].
+func clamp01(v float32) float32 {
+	return max(min(v, 1), 0)
+}
@@ -0,0 +1,105 @@
+package codec_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResizePicture(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(TestImage))
+	assert.Nil(t, err)
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+
+	filters := []codec.ResampleFilter{
+		codec.ResampleFilterNearest,
+		codec.ResampleFilterBilinear,
+		codec.ResampleFilterBicubic,
+		codec.ResampleFilterLanczos,
+	}
+
+	for _, filter := range filters {
+		resized, err := picture.Resize(picture.Width/3, picture.Height/2, filter)
+		assert.Nil(t, err, filter.String())
+		assert.Equal(t, picture.Width/3, resized.Width)
+		assert.Equal(t, picture.Height/2, resized.Height)
+		assert.Equal(t, picture.PixFormat, resized.PixFormat)
+		assert.Len(t, resized.Pix, codec.PixFormatRGBA.PixSize(
+			int(resized.Width), int(resized.Height)))
+
+		// The hash matches the resampled pixels.
+		compressedPicture, err := resized.Compress()
+		assert.Nil(t, err)
+		decompressedPicture, err := compressedPicture.Decompress()
+		assert.Nil(t, err)
+		assert.Equal(t, resized.Hash, decompressedPicture.Hash)
+	}
+
+	// Resizing to the same size with
+	// the nearest filter is lossless.
+	resized, err := picture.Resize(picture.Width, picture.Height,
+		codec.ResampleFilterNearest)
+	assert.Nil(t, err)
+	assert.Equal(t, picture.Pix, resized.Pix)
+
+	_, err = picture.Resize(0, 1, codec.ResampleFilterBilinear)
+	assert.NotNil(t, err)
+}
+
+func TestResizeAlpha(t *testing.T) {
+	// The opaque red stripe surrounded
+	// by the transparent black pixels.
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+
+	for y := 0; y < 8; y++ {
+		for x := 3; x < 5; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+
+	for _, filter := range []codec.ResampleFilter{
+		codec.ResampleFilterBilinear,
+		codec.ResampleFilterBicubic,
+		codec.ResampleFilterLanczos,
+	} {
+		for _, size := range []int32{3, 13} {
+			resized, err := picture.Resize(size, size, filter)
+			assert.Nil(t, err)
+
+			// The visible pixels stay
+			// pure red without dark fringes.
+			for i := 0; i < len(resized.Pix); i += 4 {
+				if resized.Pix[i+3] > 0 {
+					assert.Equal(t, []byte{255, 0, 0},
+						resized.Pix[i:i+3], filter.String())
+				}
+			}
+		}
+	}
+}
+
+func TestResizePalettedNearest(t *testing.T) {
+	picture := testPictureRGBA(t)
+	palettedPicture, err := picture.Quantize(16, false)
+	assert.Nil(t, err)
+
+	resized, err := palettedPicture.Resize(
+		palettedPicture.Width/2, palettedPicture.Height/3, codec.ResampleFilterNearest)
+	assert.Nil(t, err)
+	assert.Equal(t, codec.PixFormatPaletted, resized.PixFormat)
+	assert.Equal(t, palettedPicture.Palette, resized.Palette)
+	assert.Equal(t, int(resized.Width*resized.Height), len(resized.Pix))
+
+	resized, err = palettedPicture.Resize(
+		palettedPicture.Width/2, palettedPicture.Height/3, codec.ResampleFilterBilinear)
+	assert.Nil(t, err)
+	assert.Equal(t, codec.PixFormatPaletted, resized.PixFormat)
+}
@@ -2,7 +2,9 @@ package codec_test
 
 import (
 	"bytes"
+	"encoding/binary"
 	"image"
+	"math"
 	"testing"
 
 	"github.com/alacrity-engine/core/math/geometry"
@@ -20,7 +22,7 @@ func TestSerializePicture(t *testing.T) {
 
 	data, err := compressedPicture.ToBytes()
 	assert.Nil(t, err)
-	assert.Equal(t, 7219, len(data))
+	assert.Equal(t, 6507977, len(data))
 }
 
 func TestDeserializePicture(t *testing.T) {
@@ -33,11 +35,11 @@ func TestDeserializePicture(t *testing.T) {
 
 	data, err := compressedPicture.ToBytes()
 	assert.Nil(t, err)
-	assert.Equal(t, 7219, len(data))
+	assert.Equal(t, 6507977, len(data))
 
 	deserializedPicture, err := codec.CompressedPictureFromBytes(data)
 	assert.Nil(t, err)
-	assert.Equal(t, 7155, len(deserializedPicture.CompressedPix))
+	assert.Equal(t, 6507877, len(deserializedPicture.CompressedPix))
 	assert.Equal(t, codec.HashAlgorithmKeccak256, deserializedPicture.OriginalHashAlgorithm)
 	assert.Equal(t, codec.CompressionAlgorithmLZWOrderLSBLitWidth8, deserializedPicture.CompressionAlgorithm)
 }
@@ -77,3 +79,113 @@ func TestDeserializeAnimationData(t *testing.T) {
 	assert.ElementsMatch(t, animData.Frames, restoredAnimData.Frames)
 	assert.ElementsMatch(t, animData.Durations, restoredAnimData.Durations)
 }
+
+func TestDeserializeLegacyCompressedFrames(t *testing.T) {
+	// The unversioned layout lacks
+	// the compression algorithm.
+	var legacy bytes.Buffer
+	binary.Write(&legacy, binary.BigEndian, []int32{2, 5, 3})
+	legacy.Write([]byte{1, 2, 3})
+
+	frames, err := codec.CompressedFramesFromBytes(legacy.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), frames.FrameCount)
+	assert.Equal(t, int32(5), frames.OrigDataLength)
+	assert.Equal(t, []byte{1, 2, 3}, frames.Data)
+	assert.Equal(t, codec.CompressionAlgorithmLZWOrderLSBLitWidth8, frames.CompressionAlgorithm)
+
+	frames.CompressionAlgorithm = codec.CompressionAlgorithmLZ4
+	data, err := frames.ToBytes()
+	assert.Nil(t, err)
+	restoredFrames, err := codec.CompressedFramesFromBytes(data)
+	assert.Nil(t, err)
+	assert.Equal(t, frames, restoredFrames)
+}
+
+func TestDeserializeLegacyPicture(t *testing.T) {
+	picture := &codec.PictureData{
+		Width:  2,
+		Height: 1,
+		Pix:    []byte{255, 0, 0, 255, 0, 0, 255, 128},
+	}
+	hash, err := codec.Hash(picture.Pix)
+	assert.Nil(t, err)
+	picture.Hash = hash
+	compressedPicture, err := picture.Compress()
+	assert.Nil(t, err)
+
+	// The baseline layout ends
+	// with the compression algorithm.
+	var legacy bytes.Buffer
+	binary.Write(&legacy, binary.BigEndian, []int32{
+		compressedPicture.Width, compressedPicture.Height,
+		compressedPicture.OriginalPixSize,
+		int32(len(compressedPicture.CompressedPix)),
+	})
+	legacy.Write(compressedPicture.CompressedPix)
+	binary.Write(&legacy, binary.BigEndian, int32(len(compressedPicture.OriginalHash)))
+	legacy.Write(compressedPicture.OriginalHash)
+	binary.Write(&legacy, binary.BigEndian, []int32{
+		int32(compressedPicture.OriginalPixFormat),
+		int32(compressedPicture.OriginalHashAlgorithm),
+		int32(compressedPicture.CompressionAlgorithm),
+	})
+
+	legacyPicture, err := codec.CompressedPictureFromBytes(legacy.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, codec.PixFilterNone, legacyPicture.PixFilter)
+	assert.Equal(t, codec.OriginBottomLeft, legacyPicture.Origin)
+	assert.Equal(t, 1, legacyPicture.MipmapLevels())
+
+	decompressedPicture, err := legacyPicture.Decompress()
+	assert.Nil(t, err)
+	assert.Equal(t, picture.Pix, decompressedPicture.Pix)
+
+	// The versioned data must be complete.
+	data, err := compressedPicture.ToBytes()
+	assert.Nil(t, err)
+	_, err = codec.CompressedPictureFromBytes(data[:len(data)-4])
+	assert.NotNil(t, err)
+}
+
+func TestDeserializeCorruptedPicture(t *testing.T) {
+	picture := &codec.PictureData{
+		Width:  2,
+		Height: 1,
+		Pix:    []byte{255, 0, 0, 255, 0, 0, 255, 128},
+	}
+	hash, err := codec.Hash(picture.Pix)
+	assert.Nil(t, err)
+	picture.Hash = hash
+	compressedPicture, err := picture.CompressParallel(4, 1)
+	assert.Nil(t, err)
+
+	data, err := compressedPicture.ToBytes()
+	assert.Nil(t, err)
+	_, err = codec.CompressedPictureFromBytes(data)
+	assert.Nil(t, err)
+
+	// The chunk count follows the header, the sizes,
+	// the compressed pixels, the hash, the formats,
+	// the algorithms, the filter and the chunk size.
+	offset := 8 + 12 + 4 + len(compressedPicture.CompressedPix) +
+		4 + len(compressedPicture.OriginalHash) + 12 + 4 + 4
+	assert.Equal(t, uint32(len(compressedPicture.CompressedChunkSizes)),
+		binary.BigEndian.Uint32(data[offset:]))
+
+	for _, chunkCount := range []int32{-1, 1 << 30, math.MaxInt32} {
+		corruptedData := bytes.Clone(data)
+		binary.BigEndian.PutUint32(corruptedData[offset:], uint32(chunkCount))
+		_, err = codec.CompressedPictureFromBytes(corruptedData)
+		assert.NotNil(t, err)
+	}
+
+	// So are the corrupted lengths
+	// of the compressed pixels.
+	for _, length := range []int32{-1, math.MaxInt32} {
+		corruptedData := bytes.Clone(data)
+		binary.BigEndian.PutUint32(corruptedData[20:], uint32(length))
+		_, err = codec.CompressedPictureFromBytes(corruptedData)
+		assert.NotNil(t, err)
+	}
+}
@@ -0,0 +1,284 @@
+package codec
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrInvalidSignature is returned when the
+	// resource signature doesn't match its data.
+	ErrInvalidSignature = errors.New("invalid resource signature")
+	// ErrUnknownSigningKey is returned when the
+	// keyring has no key the resource was signed with.
+	ErrUnknownSigningKey = errors.New("unknown signing key")
+)
+
+// signatureHeaderSize is the size of the hash
+// algorithm identifier preceding the signature.
+const signatureHeaderSize = 4
+
+// Keyring is a thread-safe set of the
+// trusted public keys by their identifiers.
+type Keyring struct {
+	mutex sync.RWMutex
+	keys  map[string]ed25519.PublicKey
+}
+
+// NewKeyring creates a new empty keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{
+		keys: map[string]ed25519.PublicKey{},
+	}
+}
+
+// AddKey adds the trusted public key
+// to the keyring under the identifier.
+func (keyring *Keyring) AddKey(keyID string, pubKey ed25519.PublicKey) error {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return fmt.Errorf(
+			"public key '%s' is %d bytes long instead of %d",
+			keyID, len(pubKey), ed25519.PublicKeySize)
+	}
+
+	keyring.mutex.Lock()
+	defer keyring.mutex.Unlock()
+
+	if _, ok := keyring.keys[keyID]; ok {
+		return fmt.Errorf(
+			"public key '%s' is already in the keyring", keyID)
+	}
+
+	keyring.keys[keyID] = pubKey
+
+	return nil
+}
+
+// Key returns the public key
+// with the given identifier.
+func (keyring *Keyring) Key(keyID string) (ed25519.PublicKey, bool) {
+	keyring.mutex.RLock()
+	defer keyring.mutex.RUnlock()
+
+	pubKey, ok := keyring.keys[keyID]
+
+	return pubKey, ok
+}
+
+// SignResource computes the detached Ed25519 signature
+// of the serialized resource over its hash computed
+// with the consented hash algorithm.
+func SignResource(data []byte, privKey ed25519.PrivateKey) ([]byte, error) {
+	return DefaultCodec().SignResource(data, privKey)
+}
+
+// VerifyResource checks the detached Ed25519
+// signature of the serialized resource.
+func VerifyResource(data, sig []byte, pubKey ed25519.PublicKey) error {
+	if len(sig) != signatureHeaderSize+ed25519.SignatureSize {
+		return fmt.Errorf("%w: signature is %d bytes long",
+			ErrInvalidSignature, len(sig))
+	}
+
+	if len(pubKey) != ed25519.PublicKeySize {
+		return fmt.Errorf(
+			"public key is %d bytes long instead of %d",
+			len(pubKey), ed25519.PublicKeySize)
+	}
+
+	hashAlgorithm := HashAlgorithm(int32(binary.BigEndian.Uint32(sig)))
+
+	if isChecksum(hashAlgorithm) {
+		return fmt.Errorf("%w: signed with checksum '%s'",
+			ErrInvalidSignature, hashAlgorithm)
+	}
+
+	message, err := signedMessage(data, hashAlgorithm)
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	if !ed25519.Verify(pubKey, message, sig[signatureHeaderSize:]) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// SignResource computes the detached Ed25519 signature
+// of the serialized resource over its hash computed
+// with the hash algorithm of the codec.
+func (c *Codec) SignResource(data []byte, privKey ed25519.PrivateKey) ([]byte, error) {
+	if len(privKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf(
+			"private key is %d bytes long instead of %d",
+			len(privKey), ed25519.PrivateKeySize)
+	}
+
+	if isChecksum(c.HashAlgorithm) {
+		return nil, fmt.Errorf(
+			"hash algorithm '%s' is not suitable for signing",
+			c.HashAlgorithm)
+	}
+
+	message, err := signedMessage(data, c.HashAlgorithm)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sig := make([]byte, signatureHeaderSize, signatureHeaderSize+ed25519.SignatureSize)
+	binary.BigEndian.PutUint32(sig, uint32(c.HashAlgorithm))
+
+	return append(sig, ed25519.Sign(privKey, message)...), nil
+}
+
+// isChecksum reports whether the hash algorithm is
+// a non-cryptographic checksum which collisions
+// are easy to find.
+func isChecksum(hashAlgorithm HashAlgorithm) bool {
+	switch hashAlgorithm {
+	case HashAlgorithmCRC32C, HashAlgorithmCRC64, HashAlgorithmFNV1a:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// signedMessage returns the message actually signed
+// for the resource: the hash algorithm identifier
+// followed by the hash of the resource data.
+func signedMessage(data []byte, hashAlgorithm HashAlgorithm) ([]byte, error) {
+	hashCodec := DefaultCodec()
+	hashCodec.HashAlgorithm = hashAlgorithm
+	digest, err := hashCodec.Hash(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	message := make([]byte, signatureHeaderSize, signatureHeaderSize+len(digest))
+	binary.BigEndian.PutUint32(message, uint32(hashAlgorithm))
+
+	return append(message, digest...), nil
+}
+
+// SignedResourceData is a serialized resource
+// along with its detached signature and the
+// identifier of the key it was signed with.
+type SignedResourceData struct {
+	KeyID     string
+	Signature []byte
+	Data      []byte
+}
+
+// NewSignedResource signs the serialized resource
+// with the private key known under the identifier.
+func NewSignedResource(data []byte, keyID string, privKey ed25519.PrivateKey) (*SignedResourceData, error) {
+	sig, err := SignResource(data, privKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedResourceData{
+		KeyID:     keyID,
+		Signature: sig,
+		Data:      data,
+	}, nil
+}
+
+// Verify checks the signature of the resource
+// against the key from the keyring.
+func (srd *SignedResourceData) Verify(keyring *Keyring) error {
+	pubKey, ok := keyring.Key(srd.KeyID)
+
+	if !ok {
+		return fmt.Errorf("%w: '%s'", ErrUnknownSigningKey, srd.KeyID)
+	}
+
+	return VerifyResource(srd.Data, srd.Signature, pubKey)
+}
+
+func (srd *SignedResourceData) ToBytes() ([]byte, error) {
+	buffer := bytes.NewBuffer([]byte{})
+
+	err := binary.Write(buffer, binary.BigEndian, int32(len(srd.KeyID)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = buffer.WriteString(srd.KeyID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(buffer, binary.BigEndian, int32(len(srd.Signature)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = buffer.Write(srd.Signature)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(buffer, binary.BigEndian, int32(len(srd.Data)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = buffer.Write(srd.Data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// SignedResourceFromBytes restores the signed resource
+// and verifies its signature with the key looked up
+// in the keyring.
+func SignedResourceFromBytes(data []byte, keyring *Keyring) (*SignedResourceData, error) {
+	buffer := bytes.NewBuffer(data)
+	srd := &SignedResourceData{}
+
+	keyIDData, err := readLengthPrefixed(buffer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	srd.KeyID = string(keyIDData)
+	srd.Signature, err = readLengthPrefixed(buffer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	srd.Data, err = readLengthPrefixed(buffer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = srd.Verify(keyring)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return srd, nil
+}
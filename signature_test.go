@@ -0,0 +1,59 @@
+package codec_test
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignResource(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+
+	canvas := &codec.CanvasData{Name: "cirno", DrawZ: 9}
+	data, err := canvas.ToBytes()
+	assert.Nil(t, err)
+
+	sig, err := codec.SignResource(data, privKey)
+	assert.Nil(t, err)
+	assert.Nil(t, codec.VerifyResource(data, sig, pubKey))
+
+	data[0]++
+	assert.ErrorIs(t, codec.VerifyResource(data, sig, pubKey), codec.ErrInvalidSignature)
+	data[0]--
+
+	_, err = codec.NewCodec(codec.HashAlgorithmCRC32C, codec.PixFormatRGBA,
+		codec.CompressionAlgorithmLZWOrderLSBLitWidth8).SignResource(data, privKey)
+	assert.NotNil(t, err)
+}
+
+func TestSignedResourceFromBytes(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+	keyring := codec.NewKeyring()
+	assert.Nil(t, keyring.AddKey("build-server", pubKey))
+
+	canvas := &codec.CanvasData{Name: "cirno", DrawZ: 9}
+	data, err := canvas.ToBytes()
+	assert.Nil(t, err)
+
+	signedResource, err := codec.NewSignedResource(data, "build-server", privKey)
+	assert.Nil(t, err)
+	signedData, err := signedResource.ToBytes()
+	assert.Nil(t, err)
+
+	restoredResource, err := codec.SignedResourceFromBytes(signedData, keyring)
+	assert.Nil(t, err)
+	restoredCanvas, err := codec.CanvasDataFromBytes(restoredResource.Data)
+	assert.Nil(t, err)
+	assert.Equal(t, canvas, restoredCanvas)
+
+	signedData[len(signedData)-1]++
+	_, err = codec.SignedResourceFromBytes(signedData, keyring)
+	assert.ErrorIs(t, err, codec.ErrInvalidSignature)
+
+	_, err = codec.SignedResourceFromBytes(signedData, codec.NewKeyring())
+	assert.ErrorIs(t, err, codec.ErrUnknownSigningKey)
+}
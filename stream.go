@@ -0,0 +1,216 @@
+package codec
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/lzw"
+	"compress/zlib"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// CompressWriterFunc creates a writer compressing
+// the data written into it with the specified
+// compression level.
+type CompressWriterFunc func(w io.Writer, level int) (io.WriteCloser, error)
+
+// DecompressReaderFunc creates a reader
+// decompressing the data read from r.
+type DecompressReaderFunc func(r io.Reader) (io.ReadCloser, error)
+
+// RegisterCompressionStreams makes the registered
+// compression algorithm support streaming.
+func RegisterCompressionStreams(
+	id CompressionAlgorithm,
+	newWriter CompressWriterFunc,
+	newReader DecompressReaderFunc,
+) error {
+	if newWriter == nil || newReader == nil {
+		return fmt.Errorf(
+			"no stream constructors for compression algorithm %d", id)
+	}
+
+	compressionAlgorithmsMutex.Lock()
+	defer compressionAlgorithmsMutex.Unlock()
+
+	entry, ok := compressionAlgorithms[id]
+
+	if !ok {
+		return fmt.Errorf(
+			"compression algorithm %d not found", id)
+	}
+
+	if entry.blockOnly {
+		return fmt.Errorf(
+			"compression algorithm '%s' is a block format and streaming is unsupported",
+			entry.name)
+	}
+
+	if entry.newWriter != nil {
+		return fmt.Errorf(
+			"compression algorithm '%s' already supports streaming",
+			entry.name)
+	}
+
+	streamingEntry := *entry
+	streamingEntry.newWriter = newWriter
+	streamingEntry.newReader = newReader
+	compressionAlgorithms[id] = &streamingEntry
+
+	return nil
+}
+
+// NewCompressWriter returns a writer compressing
+// the data written into it with the specified
+// algorithm. The writer must be closed to
+// flush the compressed data to w.
+func NewCompressWriter(w io.Writer, alg CompressionAlgorithm) (io.WriteCloser, error) {
+	c := DefaultCodec()
+	c.CompressionAlgorithm = alg
+
+	return c.NewCompressWriter(w)
+}
+
+// NewDecompressReader returns a reader decompressing
+// the data read from r with the specified algorithm.
+func NewDecompressReader(r io.Reader, alg CompressionAlgorithm) (io.ReadCloser, error) {
+	c := DefaultCodec()
+	c.CompressionAlgorithm = alg
+
+	return c.NewDecompressReader(r)
+}
+
+// NewCompressWriter returns a writer compressing
+// the data written into it with the compression
+// algorithm and level of the codec.
+func (c *Codec) NewCompressWriter(w io.Writer) (io.WriteCloser, error) {
+	entry, ok := lookupCompressionAlgorithm(c.CompressionAlgorithm)
+
+	if !ok {
+		return nil, fmt.Errorf(
+			"compression algorithm %d not found",
+			c.CompressionAlgorithm)
+	}
+
+	if entry.newWriter == nil {
+		return nil, fmt.Errorf(
+			"compression algorithm '%s' doesn't support streaming",
+			entry.name)
+	}
+
+	return entry.newWriter(w, c.CompressionLevel)
+}
+
+// NewDecompressReader returns a reader decompressing
+// the data read from r with the compression
+// algorithm of the codec.
+func (c *Codec) NewDecompressReader(r io.Reader) (io.ReadCloser, error) {
+	entry, ok := lookupCompressionAlgorithm(c.CompressionAlgorithm)
+
+	if !ok {
+		return nil, fmt.Errorf(
+			"decompression algorithm %d not found",
+			c.CompressionAlgorithm)
+	}
+
+	if entry.newReader == nil {
+		return nil, fmt.Errorf(
+			"compression algorithm '%s' doesn't support streaming",
+			entry.name)
+	}
+
+	return entry.newReader(r)
+}
+
+// decompressHashing decompresses sourceSize bytes with
+// the streaming reader of the compression algorithm
+// and writes them to the hasher as they are produced.
+// It reports false if the algorithm doesn't support
+// streaming.
+func (c *Codec) decompressHashing(in []byte, sourceSize int, hasher hash.Hash) ([]byte, bool, error) {
+	entry, ok := lookupCompressionAlgorithm(c.CompressionAlgorithm)
+
+	if !ok || entry.newReader == nil {
+		return nil, false, nil
+	}
+
+	reader, err := entry.newReader(bytes.NewReader(in))
+
+	if err != nil {
+		return nil, true, err
+	}
+
+	defer reader.Close()
+
+	source, err := readDecompressed(io.TeeReader(reader, hasher), sourceSize)
+
+	return source, true, err
+}
+
+// newLZWOrderLSBLitWidth8Writer creates a writer
+// for the LZWOrderLSBLitWidth8 compression algorithm.
+func newLZWOrderLSBLitWidth8Writer(w io.Writer, level int) (io.WriteCloser, error) {
+	return lzw.NewWriter(w, lzw.LSB, 8), nil
+}
+
+// newLZWOrderLSBLitWidth8Reader creates a reader
+// for the LZWOrderLSBLitWidth8 compression algorithm.
+func newLZWOrderLSBLitWidth8Reader(r io.Reader) (io.ReadCloser, error) {
+	return lzw.NewReader(r, lzw.LSB, 8), nil
+}
+
+// newDeflateWriter creates a writer
+// for the DEFLATE compression algorithm.
+func newDeflateWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	flateLevel, err := flateCompressionLevel(level)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return flate.NewWriter(w, flateLevel)
+}
+
+// newDeflateReader creates a reader
+// for the DEFLATE compression algorithm.
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+// newZlibWriter creates a writer
+// for the zlib compression algorithm.
+func newZlibWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	flateLevel, err := flateCompressionLevel(level)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return zlib.NewWriterLevel(w, flateLevel)
+}
+
+// newZlibReader creates a reader
+// for the zlib compression algorithm.
+func newZlibReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+// newGzipWriter creates a writer
+// for the gzip compression algorithm.
+func newGzipWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	flateLevel, err := flateCompressionLevel(level)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return gzip.NewWriterLevel(w, flateLevel)
+}
+
+// newGzipReader creates a reader
+// for the gzip compression algorithm.
+func newGzipReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
@@ -0,0 +1,76 @@
+package codec
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/alacrity-engine/core/math/geometry"
+)
+
+// SubPicture returns a new picture of the pixels
+// covered by the rectangle given in the coordinates
+// of the picture origin. The rectangle edges are
+// snapped to the nearest pixel boundaries so the
+// adjacent frames don't overlap.
+func (picture *PictureData) SubPicture(rect geometry.Rect) (*PictureData, error) {
+	bytesPerPixel := picture.PixFormat.BytesPerPixel()
+	stride := int(picture.Width) * bytesPerPixel
+
+	if bytesPerPixel <= 0 || len(picture.Pix) != stride*int(picture.Height) {
+		return nil, fmt.Errorf(
+			"the picture of format '%s' cannot be cropped",
+			picture.PixFormat)
+	}
+
+	rect = rect.Norm()
+	minX := int(math.Round(rect.Min.X))
+	minY := int(math.Round(rect.Min.Y))
+	maxX := int(math.Round(rect.Max.X))
+	maxY := int(math.Round(rect.Max.Y))
+
+	if minX < 0 || minY < 0 || maxX > int(picture.Width) || maxY > int(picture.Height) {
+		return nil, fmt.Errorf(
+			"the rectangle %s is out of the %dx%d picture",
+			rect, picture.Width, picture.Height)
+	}
+
+	if minX >= maxX || minY >= maxY {
+		return nil, fmt.Errorf(
+			"the rectangle %s covers no pixels", rect)
+	}
+
+	// The rows are stored starting from the
+	// origin so Y is the row index as it is.
+	pix := cropPix(picture.Pix, stride, bytesPerPixel,
+		minX, minY, maxX-minX, maxY-minY)
+	subPicture := *picture
+	subPicture.Width = int32(maxX - minX)
+	subPicture.Height = int32(maxY - minY)
+
+	return subPicture.withPix(pix, picture.PixFormat)
+}
+
+// SplitFrames returns the pictures of the frames
+// of the spritesheet in the order they are returned
+// by GetSpritesheetFrames.
+func (picture *PictureData) SplitFrames(ss *SpritesheetData) ([]*PictureData, error) {
+	frames, err := picture.GetSpritesheetFrames(ss)
+
+	if err != nil {
+		return nil, err
+	}
+
+	framePictures := make([]*PictureData, 0, len(frames))
+
+	for i, frame := range frames {
+		framePicture, err := picture.SubPicture(frame)
+
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, err)
+		}
+
+		framePictures = append(framePictures, framePicture)
+	}
+
+	return framePictures, nil
+}
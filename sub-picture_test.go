@@ -0,0 +1,113 @@
+package codec_test
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/alacrity-engine/core/math/geometry"
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+// quadrantsImage returns the 4×4 image with
+// the quadrants filled with the given colors
+// from the top-left to the bottom-right one.
+func quadrantsImage(colors [4]color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+
+	for i, c := range colors {
+		x, y := i%2*2, i/2*2
+		draw.Draw(img, image.Rect(x, y, x+2, y+2),
+			image.NewUniform(c), image.Point{}, draw.Src)
+	}
+
+	return img
+}
+
+func TestSplitFrames(t *testing.T) {
+	colors := [4]color.NRGBA{
+		{R: 255, A: 255},
+		{G: 255, A: 255},
+		{B: 255, A: 255},
+		{R: 255, G: 255, B: 255, A: 128},
+	}
+	img := quadrantsImage(colors)
+	ss := &codec.SpritesheetData{
+		Width:  2,
+		Height: 2,
+		Area: codec.AreaData{
+			PixelWidth:  4,
+			PixelHeight: 4,
+		},
+	}
+
+	for _, origin := range []codec.Origin{codec.OriginBottomLeft, codec.OriginTopLeft} {
+		c := codec.DefaultCodec()
+		c.Origin = origin
+		picture, err := c.NewPictureFromImage(img)
+		assert.Nil(t, err)
+
+		frames, err := picture.SplitFrames(ss)
+		assert.Nil(t, err)
+		assert.Len(t, frames, 4)
+
+		// The frames go row by row from
+		// the top regardless of the origin.
+		for i, frame := range frames {
+			assert.Equal(t, int32(2), frame.Width)
+			assert.Equal(t, int32(2), frame.Height)
+			assert.Equal(t, origin, frame.Origin)
+
+			frameImg, err := frame.ToImage()
+			assert.Nil(t, err)
+			assert.Equal(t, quadrantsImage([4]color.NRGBA{
+				colors[i], colors[i], colors[i], colors[i],
+			}).Pix[:16], frameImg.(*image.NRGBA).Pix, origin.String())
+
+			// The hash matches the cropped pixels.
+			compressedFrame, err := frame.Compress()
+			assert.Nil(t, err)
+			_, err = compressedFrame.Decompress()
+			assert.Nil(t, err)
+		}
+	}
+}
+
+func TestSubPicture(t *testing.T) {
+	img := quadrantsImage([4]color.NRGBA{
+		{R: 255, A: 255},
+		{G: 255, A: 255},
+		{B: 255, A: 255},
+		{A: 255},
+	})
+	picture, err := codec.NewPictureFromImage(img)
+	assert.Nil(t, err)
+
+	// The paletted picture keeps its palette.
+	palettedPicture, err := picture.ConvertTo(codec.PixFormatPaletted)
+	assert.Nil(t, err)
+	subPicture, err := palettedPicture.SubPicture(geometry.R(1, 1, 3, 2))
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), subPicture.Width)
+	assert.Equal(t, int32(1), subPicture.Height)
+	assert.Equal(t, palettedPicture.Palette, subPicture.Palette)
+
+	// The bottom row of the picture
+	// is made of the blue and black pixels.
+	subImg, err := subPicture.ToImage()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0, 0, 255, 255, 0, 0, 0, 255},
+		subImg.(*image.NRGBA).Pix)
+
+	_, err = picture.SubPicture(geometry.R(2, 2, 5, 3))
+	assert.NotNil(t, err)
+	_, err = picture.SubPicture(geometry.R(1, 1, 1, 3))
+	assert.NotNil(t, err)
+
+	bcPicture, err := picture.ConvertTo(codec.PixFormatBC1)
+	assert.Nil(t, err)
+	_, err = bcPicture.SubPicture(geometry.R(0, 0, 2, 2))
+	assert.NotNil(t, err)
+}
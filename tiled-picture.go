@@ -0,0 +1,375 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/alacrity-engine/core/math/geometry"
+)
+
+// CompressedTiledPictureData is a picture split
+// into the grid of independently compressed tiles
+// so its regions can be decompressed separately.
+// The tiles are stored row by row starting from
+// the first row of the picture pixels; the tiles
+// of the last row and column may be smaller.
+type CompressedTiledPictureData struct {
+	Width      int32
+	Height     int32
+	TileWidth  int32
+	TileHeight int32
+	Tiles      []*CompressedPictureData
+}
+
+// CompressTiled splits the picture into tiles
+// of the given size and compresses each of them
+// independently using the consented algorithms.
+func (picture *PictureData) CompressTiled(tileWidth, tileHeight int32) (*CompressedTiledPictureData, error) {
+	return DefaultCodec().CompressPictureTiled(picture, tileWidth, tileHeight)
+}
+
+// CompressPictureTiled splits the picture into tiles
+// of the given size and compresses each of them
+// independently with the settings of the codec.
+// Every tile is hashed with the hash algorithm
+// of the picture.
+func (c *Codec) CompressPictureTiled(picture *PictureData, tileWidth, tileHeight int32) (*CompressedTiledPictureData, error) {
+	if tileWidth <= 0 || tileHeight <= 0 {
+		return nil, fmt.Errorf(
+			"invalid tile size %dx%d", tileWidth, tileHeight)
+	}
+
+	bytesPerPixel := picture.PixFormat.BytesPerPixel()
+	stride := int(picture.Width) * bytesPerPixel
+
+	if bytesPerPixel <= 0 || len(picture.Pix) != stride*int(picture.Height) {
+		return nil, fmt.Errorf(
+			"the picture of format '%s' cannot be split into tiles",
+			picture.PixFormat)
+	}
+
+	tiled := &CompressedTiledPictureData{
+		Width:      picture.Width,
+		Height:     picture.Height,
+		TileWidth:  tileWidth,
+		TileHeight: tileHeight,
+	}
+	tileCodec := *c
+	tileCodec.HashAlgorithm = picture.HashAlgorithm
+
+	for y := int32(0); y < picture.Height; y += tileHeight {
+		for x := int32(0); x < picture.Width; x += tileWidth {
+			w := min(tileWidth, picture.Width-x)
+			h := min(tileHeight, picture.Height-y)
+			tilePix := cropPix(picture.Pix, stride, bytesPerPixel,
+				int(x), int(y), int(w), int(h))
+			tileHash, err := tileCodec.hashPicture(picture.Palette, tilePix)
+
+			if err != nil {
+				return nil, err
+			}
+
+			compressedTile, err := tileCodec.CompressPicture(&PictureData{
+				Width:         w,
+				Height:        h,
+				Pix:           tilePix,
+				Hash:          tileHash,
+				PixFormat:     picture.PixFormat,
+				HashAlgorithm: picture.HashAlgorithm,
+				ColorSpace:    picture.ColorSpace,
+				Palette:       picture.Palette,
+				Origin:        picture.Origin,
+			})
+
+			if err != nil {
+				return nil, err
+			}
+
+			tiled.Tiles = append(tiled.Tiles, compressedTile)
+		}
+	}
+
+	return tiled, nil
+}
+
+// Columns returns the number of tile columns.
+func (tiled *CompressedTiledPictureData) Columns() int {
+	return int((tiled.Width + tiled.TileWidth - 1) / tiled.TileWidth)
+}
+
+// Rows returns the number of tile rows.
+func (tiled *CompressedTiledPictureData) Rows() int {
+	return int((tiled.Height + tiled.TileHeight - 1) / tiled.TileHeight)
+}
+
+// Tile returns the compressed tile
+// at the given column and row.
+func (tiled *CompressedTiledPictureData) Tile(column, row int) (*CompressedPictureData, error) {
+	if column < 0 || column >= tiled.Columns() ||
+		row < 0 || row >= tiled.Rows() {
+		return nil, fmt.Errorf(
+			"tile (%d; %d) is out of the %dx%d grid",
+			column, row, tiled.Columns(), tiled.Rows())
+	}
+
+	index := row*tiled.Columns() + column
+
+	if index >= len(tiled.Tiles) {
+		return nil, fmt.Errorf(
+			"tile (%d; %d) is missing", column, row)
+	}
+
+	return tiled.Tiles[index], nil
+}
+
+// Decompress decompresses all the tiles
+// and stitches them into the whole picture.
+func (tiled *CompressedTiledPictureData) Decompress() (*PictureData, error) {
+	return tiled.DecompressRegion(geometry.R(0, 0,
+		float64(tiled.Width), float64(tiled.Height)))
+}
+
+// DecompressRegion decompresses only the tiles
+// touched by the rectangle and returns the picture
+// of the rectangle expanded to the whole pixels.
+// The hash of every touched tile is verified.
+func (tiled *CompressedTiledPictureData) DecompressRegion(rect geometry.Rect) (*PictureData, error) {
+	rect = rect.Norm()
+	minX := max(int(math.Floor(rect.Min.X)), 0)
+	minY := max(int(math.Floor(rect.Min.Y)), 0)
+	maxX := min(int(math.Ceil(rect.Max.X)), int(tiled.Width))
+	maxY := min(int(math.Ceil(rect.Max.Y)), int(tiled.Height))
+
+	if minX >= maxX || minY >= maxY {
+		return nil, fmt.Errorf(
+			"the region %s doesn't intersect the picture", rect)
+	}
+
+	if len(tiled.Tiles) == 0 {
+		return nil, fmt.Errorf("the tiled picture has no tiles")
+	}
+
+	if tiled.TileWidth <= 0 || tiled.TileHeight <= 0 {
+		return nil, fmt.Errorf(
+			"invalid tile size %dx%d", tiled.TileWidth, tiled.TileHeight)
+	}
+
+	pixFormat := tiled.Tiles[0].OriginalPixFormat
+	hashAlgorithm := tiled.Tiles[0].OriginalHashAlgorithm
+	bytesPerPixel := pixFormat.BytesPerPixel()
+
+	if bytesPerPixel <= 0 {
+		return nil, fmt.Errorf(
+			"the tiles of format '%s' cannot be stitched", pixFormat)
+	}
+	regionWidth := maxX - minX
+	regionHeight := maxY - minY
+	regionStride := regionWidth * bytesPerPixel
+	regionPix := make([]byte, regionStride*regionHeight)
+	tileWidth := int(tiled.TileWidth)
+	tileHeight := int(tiled.TileHeight)
+
+	for row := minY / tileHeight; row*tileHeight < maxY; row++ {
+		for column := minX / tileWidth; column*tileWidth < maxX; column++ {
+			compressedTile, err := tiled.Tile(column, row)
+
+			if err != nil {
+				return nil, err
+			}
+
+			tile, err := compressedTile.Decompress()
+
+			if err != nil {
+				return nil, fmt.Errorf(
+					"tile (%d; %d): %w", column, row, err)
+			}
+
+			if tile.PixFormat != pixFormat {
+				return nil, fmt.Errorf(
+					"tile (%d; %d) has format '%s' instead of '%s'",
+					column, row, tile.PixFormat, pixFormat)
+			}
+
+			// The edge tiles are cut by the picture
+			// bounds and the rest are full-sized.
+			tileX := column * tileWidth
+			tileY := row * tileHeight
+			expectedWidth := min(tileWidth, int(tiled.Width)-tileX)
+			expectedHeight := min(tileHeight, int(tiled.Height)-tileY)
+
+			if int(tile.Width) != expectedWidth || int(tile.Height) != expectedHeight {
+				return nil, fmt.Errorf(
+					"tile (%d; %d) is %dx%d instead of %dx%d",
+					column, row, tile.Width, tile.Height,
+					expectedWidth, expectedHeight)
+			}
+
+			if len(tile.Pix) != pixFormat.PixSize(expectedWidth, expectedHeight) {
+				return nil, fmt.Errorf(
+					"tile (%d; %d) has %d bytes of pixel data instead of %d",
+					column, row, len(tile.Pix),
+					pixFormat.PixSize(expectedWidth, expectedHeight))
+			}
+
+			// Copy the intersection of the
+			// tile and the region row by row.
+			fromX := max(minX, tileX)
+			toX := min(maxX, tileX+int(tile.Width))
+			fromY := max(minY, tileY)
+			toY := min(maxY, tileY+int(tile.Height))
+			tileStride := int(tile.Width) * bytesPerPixel
+			rowSize := (toX - fromX) * bytesPerPixel
+
+			for y := fromY; y < toY; y++ {
+				src := (y-tileY)*tileStride + (fromX-tileX)*bytesPerPixel
+				dst := (y-minY)*regionStride + (fromX-minX)*bytesPerPixel
+				copy(regionPix[dst:dst+rowSize], tile.Pix[src:src+rowSize])
+			}
+		}
+	}
+
+	regionCodec := DefaultCodec()
+	regionCodec.HashAlgorithm = hashAlgorithm
+	hash, err := regionCodec.hashPicture(tiled.Tiles[0].Palette, regionPix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PictureData{
+		Width:         int32(regionWidth),
+		Height:        int32(regionHeight),
+		Pix:           regionPix,
+		Hash:          hash,
+		PixFormat:     pixFormat,
+		HashAlgorithm: hashAlgorithm,
+		ColorSpace:    tiled.Tiles[0].ColorSpace,
+		Palette:       tiled.Tiles[0].Palette,
+		Origin:        tiled.Tiles[0].Origin,
+	}, nil
+}
+
+func (tiled *CompressedTiledPictureData) ToBytes() ([]byte, error) {
+	buffer := bytes.NewBuffer([]byte{})
+
+	err := binary.Write(buffer, binary.BigEndian, tiled.Width)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(buffer, binary.BigEndian, tiled.Height)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(buffer, binary.BigEndian, tiled.TileWidth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(buffer, binary.BigEndian, tiled.TileHeight)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(buffer, binary.BigEndian, int32(len(tiled.Tiles)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tile := range tiled.Tiles {
+		data, err := tile.ToBytes()
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = binary.Write(buffer, binary.BigEndian, int32(len(data)))
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = buffer.Write(data)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func CompressedTiledPictureFromBytes(data []byte) (*CompressedTiledPictureData, error) {
+	buffer := bytes.NewBuffer(data)
+	tiled := &CompressedTiledPictureData{}
+
+	err := binary.Read(buffer, binary.BigEndian, &tiled.Width)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Read(buffer, binary.BigEndian, &tiled.Height)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Read(buffer, binary.BigEndian, &tiled.TileWidth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Read(buffer, binary.BigEndian, &tiled.TileHeight)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tiled.TileWidth <= 0 || tiled.TileHeight <= 0 {
+		return nil, fmt.Errorf(
+			"invalid tile size %dx%d", tiled.TileWidth, tiled.TileHeight)
+	}
+
+	var tileCount int32
+	err = binary.Read(buffer, binary.BigEndian, &tileCount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Every tile takes at least
+	// the 4 bytes of its length.
+	if tileCount < 0 || int(tileCount)*4 > buffer.Len() {
+		return nil, fmt.Errorf(
+			"invalid tile count %d with %d bytes left",
+			tileCount, buffer.Len())
+	}
+
+	for i := int32(0); i < tileCount; i++ {
+		tileData, err := readLengthPrefixed(buffer)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tile, err := CompressedPictureFromBytes(tileData)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tiled.Tiles = append(tiled.Tiles, tile)
+	}
+
+	return tiled, nil
+}
@@ -0,0 +1,89 @@
+package codec_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/alacrity-engine/core/math/geometry"
+	codec "github.com/alacrity-engine/resource-codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecompressTiledPictureRegion(t *testing.T) {
+	const width, height = 10, 7
+	pix := make([]byte, width*height*4)
+
+	for i := range pix {
+		pix[i] = byte(i)
+	}
+
+	hash, err := codec.Hash(pix)
+	assert.Nil(t, err)
+	picture := &codec.PictureData{
+		Width:         width,
+		Height:        height,
+		Pix:           pix,
+		Hash:          hash,
+		PixFormat:     codec.PixFormatRGBA,
+		HashAlgorithm: codec.ConsentedHashAlgorithm,
+	}
+
+	compressedPicture, err := picture.CompressTiled(4, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, compressedPicture.Columns())
+	assert.Equal(t, 3, compressedPicture.Rows())
+
+	data, err := compressedPicture.ToBytes()
+	assert.Nil(t, err)
+	compressedPicture, err = codec.CompressedTiledPictureFromBytes(data)
+	assert.Nil(t, err)
+
+	wholePicture, err := compressedPicture.Decompress()
+	assert.Nil(t, err)
+	assert.Equal(t, pix, wholePicture.Pix)
+	assert.Equal(t, hash, wholePicture.Hash)
+
+	region, err := compressedPicture.DecompressRegion(geometry.R(3, 2, 6.5, 4))
+	assert.Nil(t, err)
+	assert.Equal(t, int32(4), region.Width)
+	assert.Equal(t, int32(2), region.Height)
+
+	for y := 0; y < 2; y++ {
+		offset := ((2+y)*width + 3) * 4
+		assert.Equal(t, pix[offset:offset+16], region.Pix[y*16:(y+1)*16])
+	}
+
+	compressedPicture.Tiles[4].OriginalHash[0]++
+	_, err = compressedPicture.DecompressRegion(geometry.R(0, 0, 1, 1))
+	assert.Nil(t, err)
+	_, err = compressedPicture.DecompressRegion(geometry.R(5, 4, 6, 5))
+	assert.ErrorIs(t, err, codec.ErrHashMismatch)
+
+	// The tile of the wrong size is
+	// rejected instead of overrunning.
+	edgeTiles, err := codec.CompressedTiledPictureFromBytes(data)
+	assert.Nil(t, err)
+	compressedPicture.Tiles[0] = edgeTiles.Tiles[8]
+	_, err = compressedPicture.DecompressRegion(geometry.R(0, 0, 4, 3))
+	assert.NotNil(t, err)
+
+	// The corrupted tile count and
+	// tile lengths are rejected.
+	for _, corruption := range []struct {
+		offset int
+		value  int32
+	}{
+		{16, -1},
+		{16, math.MaxInt32},
+		{20, -4},
+		{20, math.MaxInt32},
+	} {
+		corruptedData := bytes.Clone(data)
+		binary.BigEndian.PutUint32(corruptedData[corruption.offset:],
+			uint32(corruption.value))
+		_, err = codec.CompressedTiledPictureFromBytes(corruptedData)
+		assert.NotNil(t, err)
+	}
+}
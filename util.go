@@ -1,6 +1,10 @@
 package codec
 
-import "image"
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
 
 // sliceEqual is a generic function for
 // slice element-wise equality comparison.
@@ -18,28 +22,66 @@ func sliceEqual[T comparable](left, right []T) bool {
 	return true
 }
 
-// reversePix reverses the pixel data
-// of the image.
-func reversePix(arr []byte) {
-	start := 0
-	end := len(arr) - 4
-
-	for start < end {
-		for i := 0; i < 4; i++ {
-			temp := arr[start+i]
-			arr[start+i] = arr[end+i]
-			arr[end+i] = temp
-		}
+// flipRows flips the pixel data
+// of the image upside down.
+func flipRows(pix []byte, stride int) {
+	temp := make([]byte, stride)
 
-		start += 4
-		end -= 4
+	for top, bottom := 0, len(pix)-stride; top < bottom; top, bottom = top+stride, bottom-stride {
+		copy(temp, pix[top:top+stride])
+		copy(pix[top:top+stride], pix[bottom:bottom+stride])
+		copy(pix[bottom:bottom+stride], temp)
 	}
 }
 
-// mirror mirrors the image
-// about the vertical axis.
-func mirror(img *image.RGBA) {
-	for i := 0; i < img.Rect.Dy(); i++ {
-		reversePix(img.Pix[4*i*img.Rect.Dx() : 4*(i+1)*img.Rect.Dx()])
+// abs returns the absolute
+// value of the integer.
+func abs(x int) int {
+	if x < 0 {
+		return -x
 	}
+
+	return x
+}
+
+// cropPix copies the w×h region starting
+// at column x and row y out of the pixel data
+// of the picture having the given stride.
+func cropPix(pix []byte, stride, bytesPerPixel, x, y, w, h int) []byte {
+	rowSize := w * bytesPerPixel
+	cropped := make([]byte, rowSize*h)
+
+	for row := 0; row < h; row++ {
+		offset := (y+row)*stride + x*bytesPerPixel
+		copy(cropped[row*rowSize:(row+1)*rowSize], pix[offset:offset+rowSize])
+	}
+
+	return cropped
+}
+
+// readLengthPrefixed reads the byte sequence
+// preceded by its int32 length out of the buffer
+// making sure the length is sane.
+func readLengthPrefixed(buffer *bytes.Buffer) ([]byte, error) {
+	var length int32
+	err := binary.Read(buffer, binary.BigEndian, &length)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if length < 0 || int(length) > buffer.Len() {
+		return nil, fmt.Errorf(
+			"invalid length %d with %d bytes left",
+			length, buffer.Len())
+	}
+
+	data := make([]byte, length)
+	_, err = buffer.Read(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
 }